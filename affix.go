@@ -0,0 +1,140 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// affixFile is the name of project affix rule files that may be placed
+// alongside .words files to define additional affix rules.
+const affixFile = ".words.aff"
+
+// mergeAffixes writes the hunspell affix file at base followed by the
+// affix rules in each of the project affix files in extra to dst. It is
+// an error for a project affix file to redefine a flag defined by base
+// or by another project affix file, to use a flag type other than that
+// of base, or to define flag aliases. Project affix files cannot be used
+// with a base that defines flag aliases, since the flags of .words
+// entries would then be read as alias numbers.
+func mergeAffixes(dst io.Writer, base string, extra []string) error {
+	m := affixMerge{defined: make(map[string]string)}
+	err := m.copy(dst, base, true)
+	if err != nil {
+		return err
+	}
+	if m.aliases != "" && len(extra) != 0 {
+		return fmt.Errorf("cannot use project affix file %s with flag aliases defined at %s", extra[0], m.aliases)
+	}
+	for _, path := range extra {
+		err := m.copy(dst, path, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// affixMerge is the state of a merge of affix files.
+type affixMerge struct {
+	// flagType is the flag type of the base
+	// affix file, empty for single character
+	// flags.
+	flagType string
+
+	// aliases is the position of the first
+	// flag or morphology alias in the base
+	// affix file, empty if there are none.
+	aliases string
+
+	// defined holds the path of the affix file
+	// that defined each affix class flag.
+	defined map[string]string
+}
+
+// copy copies the affix file at path to dst, recording the flags of the
+// affix classes it defines. If isBase is true, the flag type and any
+// aliases of the file are recorded, otherwise the file is checked for
+// compatibility with the base affix file.
+func (m *affixMerge) copy(dst io.Writer, path string, isBase bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(f)
+	for i := 1; sc.Scan(); i++ {
+		line := sc.Text()
+		fields := strings.Fields(line)
+		if len(fields) > 1 {
+			switch fields[0] {
+			case "FLAG":
+				if isBase {
+					m.flagType = fields[1]
+					break
+				}
+				if fields[1] != m.flagType {
+					return fmt.Errorf("affix flag type %q at %s:%d does not match base flag type %s", fields[1], path, i, flagTypeName(m.flagType))
+				}
+			case "AF", "AM":
+				if isBase {
+					if m.aliases == "" {
+						m.aliases = fmt.Sprintf("%s:%d", path, i)
+					}
+					break
+				}
+				return fmt.Errorf("cannot define aliases in project affix file at %s:%d", path, i)
+			case "PFX", "SFX":
+				flag := fields[1]
+				if !isBase && !isValidFlag(flag, m.flagType) {
+					return fmt.Errorf("affix flag %q at %s:%d is not a valid %s flag", flag, path, i, flagTypeName(m.flagType))
+				}
+				if prev, ok := m.defined[flag]; ok && !seen[flag] {
+					return fmt.Errorf("affix flag %q at %s:%d already defined in %s", flag, path, i, prev)
+				}
+				m.defined[flag] = path
+				seen[flag] = true
+			}
+		}
+		_, err = fmt.Fprintln(dst, line)
+		if err != nil {
+			return fmt.Errorf("failed to write affix rules: %v", err)
+		}
+	}
+	return sc.Err()
+}
+
+// isValidFlag returns whether flag is a valid affix flag for the hunspell
+// flag type typ.
+func isValidFlag(flag, typ string) bool {
+	switch typ {
+	case "long":
+		return len(flag) == 2
+	case "num":
+		n, err := strconv.Atoi(flag)
+		return err == nil && 0 < n && n < 65510
+	case "UTF-8":
+		return utf8.RuneCountInString(flag) == 1
+	default:
+		return len(flag) == 1
+	}
+}
+
+// flagTypeName returns the name of the hunspell flag type typ for use
+// in messages.
+func flagTypeName(typ string) string {
+	if typ == "" {
+		return "single character"
+	}
+	return typ
+}
@@ -0,0 +1,159 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// attributesFile is the name of files holding git attributes.
+const attributesFile = ".gitattributes"
+
+// assetAttributes is the set of git attributes that mark embedded files
+// as assets that should not be checked.
+var assetAttributes = []string{"binary", "linguist-generated", "export-ignore"}
+
+// attrRule is a git attributes pattern and the state of the attributes
+// it specifies. Attributes that are set, or have a value other than
+// false, are true. Attributes that are unset or unspecified are false.
+type attrRule struct {
+	ignoreRule
+	attrs map[string]bool
+}
+
+// matches returns whether the rule matches the file at the absolute
+// path. Unlike ignore rules, git attributes patterns only match the file
+// path itself and not its parent directories, so "assets/**" rather than
+// "assets" is needed to match the files in a directory.
+func (r attrRule) matches(path string) bool {
+	if r.dirOnly {
+		// Directory patterns do not match any files.
+		return false
+	}
+	rel, err := filepath.Rel(r.base, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	if !r.anchored {
+		rel = rel[strings.LastIndex(rel, "/")+1:]
+	}
+	return r.pattern.MatchString(rel)
+}
+
+// isAsset returns whether the file at path is marked as binary, generated
+// or excluded from archives by .gitattributes files in its directory or
+// any of its parents up to the root of its module. As with git, patterns
+// in files in deeper directories take precedence over those in shallower
+// directories, and later patterns in a file take precedence over earlier
+// patterns.
+func (ig *ignorer) isAsset(path string) (bool, error) {
+	if ig == nil {
+		return false, nil
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	dirs := ig.dirs(path)
+
+	state := make(map[string]bool)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rules, err := ig.attrsFor(dirs[i])
+		if err != nil {
+			return false, err
+		}
+		for _, r := range rules {
+			if !r.matches(path) {
+				continue
+			}
+			for attr, set := range r.attrs {
+				state[attr] = set
+			}
+		}
+	}
+	for _, attr := range assetAttributes {
+		if state[attr] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// attrsFor returns the git attributes rules held in the attributes file
+// in dir.
+func (ig *ignorer) attrsFor(dir string) ([]attrRule, error) {
+	rules, ok := ig.attrs[dir]
+	if ok {
+		return rules, nil
+	}
+	rules, err := readAttributesFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	ig.attrs[dir] = rules
+	return rules, nil
+}
+
+// readAttributesFile returns the rules in the git attributes file in dir
+// that specify asset attributes. It returns no rules and no error if the
+// file does not exist.
+func readAttributesFile(dir string) ([]attrRule, error) {
+	path := filepath.Join(dir, attributesFile)
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var rules []attrRule
+	sc := bufio.NewScanner(f)
+	for i := 1; sc.Scan(); i++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "[attr]") {
+			// Ignore blank lines, comments, lines without
+			// attributes and macro definitions.
+			continue
+		}
+		attrs := make(map[string]bool)
+		for _, a := range fields[1:] {
+			var (
+				name = a
+				set  = true
+			)
+			switch {
+			case strings.HasPrefix(a, "-"), strings.HasPrefix(a, "!"):
+				name, set = a[1:], false
+			case strings.Contains(a, "="):
+				var val string
+				name, val, _ = strings.Cut(a, "=")
+				set = val != "false"
+			}
+			if slices.Contains(assetAttributes, name) {
+				attrs[name] = set
+			}
+		}
+		if len(attrs) == 0 {
+			continue
+		}
+		r, ok, err := parseIgnoreRule(dir, fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w at %s:%d", err, path, i)
+		}
+		if ok && !r.negate {
+			rules = append(rules, attrRule{ignoreRule: r, attrs: attrs})
+		}
+	}
+	return rules, sc.Err()
+}
@@ -0,0 +1,126 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// minInitUses is the minimum number of uses of an out-of-dictionary term
+// for it to be added to the .words file by the init command.
+const minInitUses = 2
+
+// ciSnippet is the example CI configuration printed by the init command.
+const ciSnippet = `To check spelling in CI, add a step like this to a GitHub Actions workflow:
+
+    - name: Check spelling
+      run: |
+        sudo apt-get update
+        sudo apt-get install -qq libhunspell-dev hunspell-en-us
+        go install github.com/kortschak/gospel@latest
+        gospel ./...
+`
+
+// bootstrap writes a starter configuration file and .words file to the
+// module root and prints an example CI configuration. The configuration
+// file is only written if it does not already exist. Out-of-dictionary
+// terms in stats that are used at least minInitUses times are added to
+// the .words file, after confirmation if stdin is a terminal.
+func bootstrap(stats *wordStats, cfg config) int {
+	root, err := moduleRoot(".")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return internalError
+	}
+
+	path := filepath.Join(root, configFile)
+	_, err = os.Stat(path)
+	switch {
+	case err == nil:
+		fmt.Printf("%s already exists\n", rel(path))
+	case errors.Is(err, fs.ErrNotExist):
+		var buf bytes.Buffer
+		err = toml.NewEncoder(&buf).Encode(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode config: %v\n", err)
+			return internalError
+		}
+		err = os.WriteFile(path, buf.Bytes(), 0o644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write config: %v\n", err)
+			return internalError
+		}
+		fmt.Printf("wrote %s\n", rel(path))
+	default:
+		fmt.Fprintln(os.Stderr, err)
+		return internalError
+	}
+
+	var terms []string
+	for t, n := range stats.unknown {
+		if n >= minInitUses {
+			terms = append(terms, t)
+		}
+	}
+	sort.Strings(terms)
+	if isTerminal(os.Stdin) {
+		terms = confirmTerms(os.Stdin, os.Stdout, terms, stats.unknown)
+	}
+	path = filepath.Join(root, ".words")
+	if len(terms) == 0 {
+		fmt.Printf("no words added to %s\n", rel(path))
+	} else {
+		err = addWords(path, terms)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return internalError
+		}
+		noun := "words"
+		if len(terms) == 1 {
+			noun = "word"
+		}
+		fmt.Printf("added %d %s to %s\n", len(terms), noun, rel(path))
+	}
+
+	fmt.Printf("\n%s", ciSnippet)
+	return success
+}
+
+// isTerminal returns whether f is a character device.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// confirmTerms prompts on w for confirmation of each term, reading the
+// responses from r, and returns the terms that were confirmed. Terms are
+// confirmed by default.
+func confirmTerms(r io.Reader, w io.Writer, terms []string, uses map[string]int) []string {
+	sc := bufio.NewScanner(r)
+	var confirmed []string
+	for _, t := range terms {
+		fmt.Fprintf(w, "add %q (%d uses) to .words? [Y/n] ", t, uses[t])
+		if !sc.Scan() {
+			fmt.Fprintln(w)
+			break
+		}
+		switch strings.ToLower(strings.TrimSpace(sc.Text())) {
+		case "", "y", "yes":
+			confirmed = append(confirmed, t)
+		}
+	}
+	return confirmed
+}
@@ -0,0 +1,574 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxBuiltinSuggestions is the maximum number of suggestions made by the
+// builtin spelling engine.
+const maxBuiltinSuggestions = 15
+
+// builtinSpeller is a spelling engine written in Go that does not depend
+// on libhunspell. It reads hunspell affix and dictionary files, and
+// supports prefix and suffix rules, flag aliases and the NEEDAFFIX,
+// FORBIDDENWORD and ONLYINCOMPOUND flags, but not compounding, twofold
+// affixes or morphological analysis. Suggestions are words within two
+// edits of the misspelled word.
+type builtinSpeller struct {
+	rules *affixRules
+
+	// stems holds the flags of each
+	// dictionary stem.
+	stems map[string][]string
+
+	// words is the set of correct words and
+	// forbidden is the set of forbidden words.
+	words     map[string]bool
+	forbidden map[string]bool
+
+	// alphabet is the set of letters used to
+	// construct suggestions, in order of
+	// preference.
+	alphabet []rune
+}
+
+// newBuiltinSpeller returns a builtin speller with the affix rules in the
+// file aff and the words in the file dic.
+func newBuiltinSpeller(aff, dic string) (*builtinSpeller, error) {
+	rules, err := readAffixRules(aff)
+	if err != nil {
+		return nil, err
+	}
+	s := &builtinSpeller{
+		rules:     rules,
+		stems:     make(map[string][]string),
+		words:     make(map[string]bool),
+		forbidden: make(map[string]bool),
+	}
+	err = s.readDictionary(dic)
+	if err != nil {
+		return nil, err
+	}
+	s.alphabet = []rune(rules.try)
+	if len(s.alphabet) == 0 {
+		letters := make(map[rune]bool)
+		for w := range s.words {
+			for _, r := range strings.ToLower(w) {
+				if unicode.IsLetter(r) {
+					letters[r] = true
+				}
+			}
+		}
+		for r := range letters {
+			s.alphabet = append(s.alphabet, r)
+		}
+		sort.Slice(s.alphabet, func(i, j int) bool { return s.alphabet[i] < s.alphabet[j] })
+	}
+	return s, nil
+}
+
+// readDictionary adds the stems in the hunspell dictionary at path and the
+// words formed from them by affix rules.
+func (s *builtinSpeller) readDictionary(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for i := 0; sc.Scan(); i++ {
+		if i == 0 {
+			// Skip word count line.
+			continue
+		}
+		line := s.rules.decode(sc.Text())
+		if strings.HasPrefix(line, "\t") {
+			// Comment line.
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// Any fields after the first are
+		// morphological descriptions.
+		stem, flags, _ := strings.Cut(fields[0], "/")
+		if stem == "" {
+			continue
+		}
+		s.addStem(stem, s.rules.parseFlags(flags))
+	}
+	return sc.Err()
+}
+
+// addStem adds stem and the words formed from it by the affix rules named
+// by flags.
+func (s *builtinSpeller) addStem(stem string, flags []string) {
+	s.stems[stem] = flags
+	if hasFlag(flags, s.rules.forbidden) {
+		s.forbidden[stem] = true
+		return
+	}
+	if !hasFlag(flags, s.rules.needAffix) && !hasFlag(flags, s.rules.onlyInCompound) {
+		s.words[stem] = true
+	}
+	var crossed []string
+	for _, f := range flags {
+		c, ok := s.rules.classes[f]
+		if !ok || c.prefix {
+			continue
+		}
+		for _, e := range c.entries {
+			w, ok := e.apply(stem, false)
+			if !ok {
+				continue
+			}
+			s.words[w] = true
+			if c.cross {
+				crossed = append(crossed, w)
+			}
+		}
+	}
+	for _, f := range flags {
+		c, ok := s.rules.classes[f]
+		if !ok || !c.prefix {
+			continue
+		}
+		bases := []string{stem}
+		if c.cross {
+			bases = append(bases, crossed...)
+		}
+		for _, e := range c.entries {
+			for _, b := range bases {
+				w, ok := e.apply(b, true)
+				if ok {
+					s.words[w] = true
+				}
+			}
+		}
+	}
+}
+
+// hasFlag returns whether flag is in flags. The empty flag is never in
+// flags.
+func hasFlag(flags []string, flag string) bool {
+	if flag == "" {
+		return false
+	}
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// number matches numbers with optional separators, which are correct
+// regardless of the dictionary.
+var number = regexp.MustCompile(`^[0-9]+(?:[.,-][0-9]+)*$`)
+
+// IsCorrect returns whether word is correctly spelled. Words are correct
+// if they are in the dictionary, or are the capitalised or upper case form
+// of a word in the dictionary.
+func (s *builtinSpeller) IsCorrect(word string) bool {
+	if s.forbidden[word] {
+		return false
+	}
+	if s.words[word] || number.MatchString(word) {
+		return true
+	}
+	lower := strings.ToLower(word)
+	switch word {
+	case lower:
+		return false
+	case capitalise(lower):
+		return s.words[lower] && !s.forbidden[lower]
+	case strings.ToUpper(word):
+		return s.words[lower] && !s.forbidden[lower] || s.words[capitalise(lower)]
+	}
+	return false
+}
+
+// Suggest returns suggested corrections for word. Words one edit from word
+// are suggested first, followed by splits of word into two correct words.
+// If there are none of these, words two edits from word are suggested.
+// Suggestions follow the case of word.
+func (s *builtinSpeller) Suggest(word string) []string {
+	lower := strings.ToLower(word)
+	var restore func(string) string
+	switch word {
+	case capitalise(lower):
+		restore = capitalise
+	case strings.ToUpper(word):
+		restore = strings.ToUpper
+	default:
+		restore = func(w string) string { return w }
+	}
+
+	seen := map[string]bool{word: true, lower: true}
+	var suggestions []string
+	suggest := func(candidates []string) {
+		for _, c := range candidates {
+			if len(suggestions) == maxBuiltinSuggestions {
+				return
+			}
+			if seen[c] {
+				continue
+			}
+			seen[c] = true
+			switch {
+			case s.IsCorrect(restore(c)):
+				suggestions = append(suggestions, restore(c))
+			case s.words[capitalise(c)]:
+				suggestions = append(suggestions, capitalise(c))
+			}
+		}
+	}
+
+	edits := s.edits(lower)
+	suggest(edits)
+	var splits []string
+	for i := range lower {
+		if i == 0 {
+			continue
+		}
+		left, right := lower[:i], lower[i:]
+		if utf8.RuneCountInString(left) > 1 && utf8.RuneCountInString(right) > 1 && s.IsCorrect(left) && s.IsCorrect(right) {
+			splits = append(splits, left+" "+right, left+"-"+right)
+		}
+	}
+	for _, w := range splits {
+		if len(suggestions) == maxBuiltinSuggestions {
+			break
+		}
+		if !seen[w] {
+			seen[w] = true
+			suggestions = append(suggestions, restore(w))
+		}
+	}
+	if len(suggestions) != 0 {
+		return suggestions
+	}
+	for _, e := range edits {
+		suggest(s.edits(e))
+		if len(suggestions) == maxBuiltinSuggestions {
+			break
+		}
+	}
+	return suggestions
+}
+
+// edits returns the words that are a single deletion, transposition,
+// replacement or insertion from word.
+func (s *builtinSpeller) edits(word string) []string {
+	r := []rune(word)
+	var edits []string
+	for i := range r {
+		edits = append(edits, string(r[:i])+string(r[i+1:]))
+	}
+	for i := 0; i < len(r)-1; i++ {
+		t := append([]rune(nil), r...)
+		t[i], t[i+1] = t[i+1], t[i]
+		edits = append(edits, string(t))
+	}
+	for i := range r {
+		for _, c := range s.alphabet {
+			if c != r[i] {
+				edits = append(edits, string(r[:i])+string(c)+string(r[i+1:]))
+			}
+		}
+	}
+	for i := 0; i <= len(r); i++ {
+		for _, c := range s.alphabet {
+			edits = append(edits, string(r[:i])+string(c)+string(r[i:]))
+		}
+	}
+	return edits
+}
+
+// Add adds word to the dictionary.
+func (s *builtinSpeller) Add(word string) bool {
+	s.words[word] = true
+	delete(s.forbidden, word)
+	return true
+}
+
+// AddWithAffix adds word to the dictionary with the affix rules of the
+// stem example. If example is not a stem in the dictionary, word is added
+// without affix rules.
+func (s *builtinSpeller) AddWithAffix(word, example string) bool {
+	delete(s.forbidden, word)
+	s.addStem(word, s.stems[example])
+	return true
+}
+
+// capitalise returns s with its first letter in upper case.
+func capitalise(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	if n == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+// affixRules holds the rules of a hunspell affix file used by the builtin
+// spelling engine.
+type affixRules struct {
+	// flagType is the type of flags used in
+	// the affix and dictionary files.
+	flagType string
+
+	// aliases holds flag sets that may be
+	// referred to by their index from one.
+	aliases [][]string
+
+	// classes holds the affix classes defined
+	// in the file, keyed by their flag.
+	classes map[string]*affixClass
+
+	// needAffix, forbidden and onlyInCompound
+	// are the flags of stems that are not
+	// words by themselves.
+	needAffix      string
+	forbidden      string
+	onlyInCompound string
+
+	// try is the letters to try when making
+	// suggestions.
+	try string
+
+	// decode converts text in the file's
+	// encoding to UTF-8.
+	decode func(string) string
+}
+
+// affixClass is a class of prefix or suffix rules.
+type affixClass struct {
+	prefix bool
+
+	// cross indicates the class may be
+	// combined with classes of the other
+	// kind that also allow it.
+	cross bool
+
+	entries []affixEntry
+}
+
+// affixEntry is a single affix rule.
+type affixEntry struct {
+	strip, add string
+
+	// cond is the condition on the word
+	// for the rule to apply. It is nil if
+	// the rule always applies.
+	cond *regexp.Regexp
+}
+
+// apply returns the result of applying the rule to word as a prefix or
+// suffix, and whether the rule applies.
+func (e affixEntry) apply(word string, prefix bool) (string, bool) {
+	if len(e.strip) >= len(word) || (e.cond != nil && !e.cond.MatchString(word)) {
+		return "", false
+	}
+	if prefix {
+		if !strings.HasPrefix(word, e.strip) {
+			return "", false
+		}
+		return e.add + word[len(e.strip):], true
+	}
+	if !strings.HasSuffix(word, e.strip) {
+		return "", false
+	}
+	return word[:len(word)-len(e.strip)] + e.add, true
+}
+
+// readAffixRules returns the rules in the hunspell affix file at path.
+func readAffixRules(path string) (*affixRules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules := &affixRules{
+		classes: make(map[string]*affixClass),
+		decode:  func(s string) string { return s },
+	}
+	var aliases int
+	sc := bufio.NewScanner(f)
+	for i := 1; sc.Scan(); i++ {
+		fields := strings.Fields(rules.decode(sc.Text()))
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "SET":
+			rules.decode, err = decoder(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("%w at %s:%d", err, path, i)
+			}
+		case "FLAG":
+			rules.flagType = fields[1]
+		case "TRY":
+			rules.try = fields[1]
+		case "NEEDAFFIX", "PSEUDOROOT":
+			rules.needAffix = fields[1]
+		case "FORBIDDENWORD":
+			rules.forbidden = fields[1]
+		case "ONLYINCOMPOUND":
+			rules.onlyInCompound = fields[1]
+		case "AF":
+			if aliases == 0 {
+				// The first AF line holds the count.
+				aliases, err = strconv.Atoi(fields[1])
+				if err == nil && aliases > 0 {
+					continue
+				}
+				aliases = -1
+			}
+			rules.aliases = append(rules.aliases, rules.splitFlags(fields[1]))
+		case "PFX", "SFX":
+			flag := fields[1]
+			c, ok := rules.classes[flag]
+			if !ok {
+				// The first line of a class is its header.
+				if len(fields) < 4 {
+					return nil, fmt.Errorf("invalid affix class header at %s:%d", path, i)
+				}
+				rules.classes[flag] = &affixClass{prefix: fields[0] == "PFX", cross: fields[2] == "Y"}
+				continue
+			}
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("invalid affix rule at %s:%d", path, i)
+			}
+			e := affixEntry{strip: fields[2], add: fields[3]}
+			if e.strip == "0" {
+				e.strip = ""
+			}
+			// Continuation classes are not supported.
+			e.add, _, _ = strings.Cut(e.add, "/")
+			if e.add == "0" {
+				e.add = ""
+			}
+			cond := "."
+			if len(fields) > 4 {
+				cond = fields[4]
+			}
+			e.cond, err = conditionRx(cond, c.prefix)
+			if err != nil {
+				return nil, fmt.Errorf("invalid affix condition at %s:%d: %v", path, i, err)
+			}
+			c.entries = append(c.entries, e)
+		}
+	}
+	return rules, sc.Err()
+}
+
+// parseFlags returns the flags in the dictionary flag field s, resolving
+// flag aliases.
+func (r *affixRules) parseFlags(s string) []string {
+	if s == "" {
+		return nil
+	}
+	if len(r.aliases) != 0 {
+		n, err := strconv.Atoi(s)
+		if err == nil && 0 < n && n <= len(r.aliases) {
+			return r.aliases[n-1]
+		}
+	}
+	return r.splitFlags(s)
+}
+
+// splitFlags returns the flags in s according to the flag type.
+func (r *affixRules) splitFlags(s string) []string {
+	var flags []string
+	switch r.flagType {
+	case "long":
+		runes := []rune(s)
+		for i := 0; i+1 < len(runes); i += 2 {
+			flags = append(flags, string(runes[i:i+2]))
+		}
+	case "num":
+		flags = strings.Split(s, ",")
+	default:
+		for _, c := range s {
+			flags = append(flags, string(c))
+		}
+	}
+	return flags
+}
+
+// conditionRx returns a regular expression matching words that satisfy
+// the hunspell affix condition cond at their start for prefixes or their
+// end for suffixes. It returns nil if the condition is always satisfied.
+func conditionRx(cond string, prefix bool) (*regexp.Regexp, error) {
+	if cond == "." {
+		return nil, nil
+	}
+	var buf strings.Builder
+	var inClass bool
+	for _, c := range cond {
+		switch {
+		case c == '[' && !inClass:
+			inClass = true
+			buf.WriteRune(c)
+		case c == ']' && inClass:
+			inClass = false
+			buf.WriteRune(c)
+		case c == '.' && !inClass, c == '^' && inClass:
+			buf.WriteRune(c)
+		case c == '-' && inClass:
+			buf.WriteString(`\-`)
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if prefix {
+		return regexp.Compile("^(?:" + buf.String() + ")")
+	}
+	return regexp.Compile("(?:" + buf.String() + ")$")
+}
+
+// decoder returns a function converting text in the named encoding to
+// UTF-8. Only UTF-8 and ISO8859-1 are supported.
+func decoder(encoding string) (func(string) string, error) {
+	switch strings.ToUpper(encoding) {
+	case "UTF-8":
+		return func(s string) string { return s }, nil
+	case "ISO8859-1", "ISO-8859-1":
+		return func(s string) string {
+			if isASCII(s) {
+				return s
+			}
+			r := make([]rune, len(s))
+			for i := 0; i < len(s); i++ {
+				r[i] = rune(s[i])
+			}
+			return string(r)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", encoding)
+	}
+}
+
+// isASCII returns whether s holds only ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testAffixes = `SET UTF-8
+TRY esianrtolcdugmphbyfvkwz
+FORBIDDENWORD !
+NEEDAFFIX ~
+
+SFX D Y 4
+SFX D 0 d e
+SFX D y ied [^aeiou]y
+SFX D 0 ed [^ey]
+SFX D 0 ed [aeiou]y
+
+SFX S Y 3
+SFX S y ies [^aeiou]y
+SFX S 0 s [aeiou]y
+SFX S 0 s [^sxzhy]
+
+PFX R Y 1
+PFX R 0 re .
+`
+
+const testDictionary = `7
+carry/DSR
+play/DSR
+bake/D
+London
+teh/!
+kitten/~S
+`
+
+var builtinSpellerTests = []struct {
+	word string
+	want bool
+}{
+	{word: "carry", want: true},
+	{word: "carried", want: true},
+	{word: "carries", want: true},
+	{word: "recarried", want: true},
+	{word: "carryed", want: false},
+	{word: "played", want: true},
+	{word: "plays", want: true},
+	{word: "replays", want: true},
+	{word: "plaied", want: false},
+	{word: "baked", want: true},
+	{word: "rebaked", want: false},
+	{word: "Played", want: true},
+	{word: "PLAYED", want: true},
+	{word: "pLAYED", want: false},
+	{word: "London", want: true},
+	{word: "LONDON", want: true},
+	{word: "london", want: false},
+	{word: "teh", want: false},
+	{word: "kitten", want: false},
+	{word: "kittens", want: true},
+	{word: "1,000.5", want: true},
+}
+
+func TestBuiltinSpeller(t *testing.T) {
+	dir := t.TempDir()
+	aff := filepath.Join(dir, "xx_XX.aff")
+	dic := filepath.Join(dir, "xx_XX.dic")
+	err := os.WriteFile(aff, []byte(testAffixes), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(dic, []byte(testDictionary), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := newBuiltinSpeller(aff, dic)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, test := range builtinSpellerTests {
+		got := s.IsCorrect(test.word)
+		if got != test.want {
+			t.Errorf("unexpected result for %q: got:%t want:%t", test.word, got, test.want)
+		}
+	}
+
+	for word, want := range map[string][]string{
+		"carryed":   {"carried"},
+		"Plaeyd":    {"Played"},
+		"londn":     {"London"},
+		"bakedplay": {"baked play", "baked-play"},
+	} {
+		got := s.Suggest(word)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("unexpected suggestions for %q: got:%q want:%q", word, got, want)
+		}
+	}
+
+	if !s.AddWithAffix("bray", "play") || !s.IsCorrect("brayed") {
+		t.Error("expected word added with affix rules of example")
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// isCatalogName returns whether the file at path has the name of a
+// gettext .po or .pot file or a gotext JSON catalog.
+func isCatalogName(path string) bool {
+	switch filepath.Ext(path) {
+	case ".po", ".pot":
+		return true
+	}
+	return strings.HasSuffix(path, ".gotext.json")
+}
+
+// catalogFiles returns the paths of message catalog files in the modules
+// of the provided packages. Directories that are ignored by the go tool
+// and nested modules are not searched.
+func catalogFiles(pkgs []*packages.Package) ([]string, error) {
+	roots := make(map[string]bool)
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		if p.Module != nil && p.Module.Dir != "" {
+			roots[p.Module.Dir] = true
+		}
+	})
+	var paths []string
+	for r := range roots {
+		err := filepath.WalkDir(r, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path == r {
+					return nil
+				}
+				name := d.Name()
+				if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" {
+					return filepath.SkipDir
+				}
+				if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Type().IsRegular() && isCatalogName(path) {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// maskCatalog returns the text of the file at path with all text other
+// than source language messages replaced with spaces if the file is a
+// gettext .po or .pot file, or a gotext JSON catalog. Otherwise text is
+// returned unaltered.
+func maskCatalog(path, text string) string {
+	switch filepath.Ext(path) {
+	case ".po", ".pot":
+		return maskPO(text)
+	case ".json":
+		return maskGotext(text)
+	}
+	return text
+}
+
+// maskPO returns the gettext catalog text with all text other than the
+// contents of msgid and msgid_plural strings replaced with spaces.
+func maskPO(text string) string {
+	b := []byte(text)
+	var (
+		off  int
+		keep bool
+	)
+	for _, line := range strings.SplitAfter(text, "\n") {
+		start := off
+		off += len(line)
+
+		trimmed := strings.TrimLeft(line, " \t")
+		switch {
+		case strings.HasPrefix(trimmed, "msgid ") || strings.HasPrefix(trimmed, "msgid_plural "):
+			keep = true
+		case strings.HasPrefix(trimmed, `"`):
+			// A continuation of the previous keyword's string.
+		default:
+			// Translations, contexts and comments,
+			// including obsolete entries.
+			keep = false
+		}
+		first := strings.IndexByte(line, '"')
+		last := strings.LastIndexByte(line, '"')
+		for i := range line {
+			if keep && first < i && i < last {
+				continue
+			}
+			if b[start+i] != '\n' {
+				b[start+i] = ' '
+			}
+		}
+		if keep && first < last {
+			blankEscapes(b[start+first+1 : start+last])
+		}
+	}
+	return string(b)
+}
+
+// gotextCatalog is the structure of the gotext JSON catalog format used
+// by golang.org/x/text/cmd/gotext.
+type gotextCatalog struct {
+	Language string `json:"language"`
+	Messages []struct {
+		Message string `json:"message"`
+	} `json:"messages"`
+}
+
+// gotextPlaceholder matches gotext message placeholders.
+var gotextPlaceholder = regexp.MustCompile(`\{[^{}\s]+\}`)
+
+// maskGotext returns the gotext JSON catalog text with all text other than
+// the source language messages replaced with spaces. Message placeholders
+// are also replaced. If text is not a gotext catalog it is returned
+// unaltered.
+func maskGotext(text string) string {
+	var cat gotextCatalog
+	err := json.Unmarshal([]byte(text), &cat)
+	if err != nil || cat.Language == "" || cat.Messages == nil {
+		return text
+	}
+	keep := make([]bool, len(text))
+	for _, v := range jsonKeyValues(text, "message") {
+		for i := v.pos; i < v.end; i++ {
+			keep[i] = true
+		}
+	}
+	b := []byte(text)
+	for i, k := range keep {
+		if !k && b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+	blankEscapes(b)
+	return gotextPlaceholder.ReplaceAllStringFunc(string(b), blank)
+}
+
+// jsonKeyValues returns the spans of the contents of string values with
+// the given object key in the valid JSON text.
+func jsonKeyValues(text, key string) []span {
+	var (
+		values []span
+		isKey  bool
+	)
+	for i := 0; i < len(text); i++ {
+		if text[i] != '"' {
+			continue
+		}
+		start := i + 1
+		for i++; i < len(text) && text[i] != '"'; i++ {
+			if text[i] == '\\' {
+				i++
+			}
+		}
+		end := i
+
+		rest := strings.TrimLeft(text[i+1:], " \t\r\n")
+		if strings.HasPrefix(rest, ":") {
+			isKey = text[start:end] == key
+			continue
+		}
+		if isKey {
+			values = append(values, span{pos: start, end: end})
+		}
+		isKey = false
+	}
+	return values
+}
+
+// blankEscapes replaces backslash escape sequences in b with spaces.
+func blankEscapes(b []byte) {
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\\' || i+1 == len(b) {
+			continue
+		}
+		b[i] = ' '
+		if b[i+1] != '\n' {
+			b[i+1] = ' '
+		}
+		i++
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// changelogName matches the names of changelog and release notes files.
+var changelogName = regexp.MustCompile(`(?i)^(?:change(?:log|s)|history|news|release[-_]?notes)(?:\.(?:md|markdown|txt|rst))?$`)
+
+// changelogFiles returns the paths of changelog and release notes files
+// in the roots of the modules of the provided packages.
+func changelogFiles(pkgs []*packages.Package) ([]string, error) {
+	roots := make(map[string]bool)
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		if p.Module != nil && p.Module.Dir != "" {
+			roots[p.Module.Dir] = true
+		}
+	})
+	var paths []string
+	for r := range roots {
+		entries, err := os.ReadDir(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Type().IsRegular() && changelogName.MatchString(e.Name()) {
+				paths = append(paths, filepath.Join(r, e.Name()))
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+var (
+	// versionHeading matches changelog lines that are release
+	// version headings, such as "## [v1.2.3] - 2022-01-02".
+	versionHeading = regexp.MustCompile(`^(?:#{1,6}\s*)?\[?v?\d+\.\d+(?:\.\d+)*\b.*$`)
+
+	// changelogNoise is used for masking issue and pull request
+	// references, author handles and code spans in changelogs.
+	changelogNoise = regexp.MustCompile(`(?:[\w.-]+/[\w.-]+)?#\d+\b|\bGH-\d+\b|\B@[A-Za-z0-9][\w-]*|` + "`[^`]+`")
+)
+
+// maskChangelog returns the changelog line with version headings, issue
+// and pull request references, author handles and code spans replaced
+// with spaces.
+func maskChangelog(line string) string {
+	if versionHeading.MatchString(line) {
+		return blank(line)
+	}
+	return changelogNoise.ReplaceAllStringFunc(line, blank)
+}
@@ -11,17 +11,18 @@ import (
 	"go/token"
 	"io"
 	"math"
-	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/kortschak/camel"
 	"github.com/kortschak/ct"
+	"golang.org/x/tools/go/packages"
 	"mvdan.cc/xurls/v2"
 )
 
@@ -31,16 +32,163 @@ type checker struct {
 
 	dictionary *dictionary
 	camel      camel.Splitter
+
+	// dictionaries holds the dictionary state
+	// for each module root when modules use
+	// their own dictionaries, with the state
+	// for text outside modules keyed by the
+	// empty string. It is nil otherwise.
+	dictionaries map[string]*dictionaryState
+
 	heuristics []heuristic
 
+	// pathFilters is the set of where filters
+	// restricting the kinds of text to check
+	// by path.
+	pathFilters []pathFilter
+
+	// suppressed records words and lines
+	// that were not checked because of
+	// heuristics or line patterns.
+	suppressed *suppressions
+
+	// stringFocus is the set of struct
+	// fields and function arguments whose
+	// string literals are always checked.
+	stringFocus *stringFocus
+
+	// helpText is the set of string literals
+	// holding flag usage or CLI help text.
+	// These are checked with flags and
+	// placeholders masked.
+	helpText map[ast.Node]bool
+
+	// symbolRefs holds the spans of dotted
+	// references to known symbols in
+	// comments, keyed by the comment.
+	symbolRefs map[ast.Node][]span
+
+	// inFence is the set of line comments
+	// that start within a fenced code block
+	// opened by an earlier comment in their
+	// comment group.
+	inFence map[ast.Node]bool
+
+	// tableRows is the set of line comments
+	// that are rows of a table with columns
+	// aligned with those of adjacent line
+	// comments in their comment group.
+	tableRows map[ast.Node]bool
+
+	// inOutput is the set of line comments
+	// that start within an example output
+	// section begun by an earlier comment.
+	inOutput map[ast.Node]bool
+
+	// docBlocks is the state of indented doc
+	// comment spans at the start of each line
+	// comment when masking doc comment syntax.
+	docBlocks map[ast.Node]docBlock
+
+	// stats records the vocabulary of
+	// checked text. It is nil unless the
+	// stats command is being run.
+	stats *wordStats
+
+	// lintDirectives holds the linter
+	// directives naming the spelling check
+	// keyed by file name, and
+	// malformedDirectives holds the positions
+	// of those without a reason.
+	lintDirectives      map[string][]*lintDirective
+	malformedDirectives []token.Position
+
+	// skipped records content that was
+	// not checked or was only partially
+	// checked. It is nil if skipped
+	// content is not being reported.
+	skipped *skipped
+
+	// generatedFiles matches the paths of
+	// files that are generated, relative to
+	// the module root.
+	generatedFiles pathFilter
+
+	// linePatterns is the set of regular
+	// expressions matching lines to ignore.
+	linePatterns []*regexp.Regexp
+
+	// headerPatterns is the set of regular
+	// expressions identifying file header
+	// comments.
+	headerPatterns []*regexp.Regexp
+
 	changeFilter changeFilter
 
+	// addedLines is the set of lines added
+	// since the since ref, excluding diff
+	// context. It is only used to restrict
+	// suggestions to new findings.
+	addedLines changeFilter
+
+	// ignorer excludes files from checking
+	// based on .gospelignore files.
+	ignorer *ignorer
+
 	config
 
-	misspellings []misspelling
+	checked []checkedText
 
 	suggested map[string][]string
 
+	// dictSuggestions is the cache of
+	// dictionary suggestions for words,
+	// and suggestTimeouts is the number
+	// of words whose suggestions were
+	// abandoned after SuggestTimeout.
+	dictSuggestions map[string][]string
+	suggestTimeouts int
+
+	// exported is the set of comments that
+	// are part of package doc comments or
+	// doc comments of exported declarations.
+	exported map[ast.Node]bool
+
+	// failures is the number of findings
+	// at the error severity level.
+	failures int
+	// generatedFailures is the number of
+	// findings at the error severity level
+	// in generated files.
+	generatedFailures int
+
+	// style holds style rule findings for
+	// comments keyed by the comment.
+	style map[ast.Node][]finding
+
+	// consistency records the spelling
+	// variants used in the project.
+	consistency *consistency
+
+	// urlTargets confirms the targets
+	// of URLs.
+	urlTargets urlChecker
+
+	// issueRepo is the GitHub repository
+	// used to resolve bare issue references.
+	issueRepo string
+	// issues is the cache of issue
+	// reference validation results.
+	issues map[string]string
+	// papers is the cache of DOI and
+	// arXiv identifier validation results.
+	papers map[string]string
+
+	// templates is the set of Go template
+	// files to check and whether each is
+	// an HTML template.
+	templates map[string]bool
+
 	// generated is the set of files that have code generation
 	// comments.
 	generated map[string]bool
@@ -64,14 +212,19 @@ func newChecker(d *dictionary, cfg config) (*checker, error) {
 	c := &checker{
 		dictionary: d,
 		config:     cfg,
-		camel:      camel.NewSplitter([]string{"\\"}),
+		camel:      camel.NewSplitter(append([]string{"\\"}, cfg.Initialisms...)),
 		heuristics: []heuristic{
-			wordLen{cfg.MaxWordLen},
+			wordLen{min: cfg.MinWordLen, max: cfg.MaxWordLen},
 			isNakedHex{cfg.MinNakedHex},
 			isHexRune{},
 			isUnit{},
+			isRFC{},
+			newInitialisms(cfg.Initialisms, cfg.IgnoreUpper),
 		},
-		generated: make(map[string]bool),
+		generated:       make(map[string]bool),
+		dictSuggestions: make(map[string][]string),
+		lintDirectives:  make(map[string][]*lintDirective),
+		urlTargets:      urlChecker{ignored: urlMap(d.ignoredURLs)},
 		warn: map[bool]func(...interface{}) fmt.Formatter{
 			false: (ct.Italic | ct.Fg(ct.BoldRed)).Paint,    // Not generated code.
 			true:  (ct.Italic | ct.Fg(ct.BoldYellow)).Paint, // Generated code.
@@ -85,6 +238,48 @@ func newChecker(d *dictionary, cfg config) (*checker, error) {
 	if c.MakeSuggestions != never {
 		c.suggested = make(map[string][]string)
 	}
+	if c.CheckIssues {
+		c.issues = make(map[string]string)
+	}
+	if c.CheckPaperRefs {
+		c.papers = make(map[string]string)
+	}
+	if c.CheckConsistency {
+		c.consistency = newConsistency()
+	}
+	if c.ShowSuppressed {
+		c.suppressed = &suppressions{}
+	}
+	if c.ReportSkipped {
+		c.skipped = &skipped{}
+	}
+	if c.CheckFlagUsage || c.CheckCLIHelp {
+		c.helpText = make(map[ast.Node]bool)
+	}
+	if c.MaskFences {
+		c.inFence = make(map[ast.Node]bool)
+	}
+	if c.MaskDocSyntax {
+		c.docBlocks = make(map[ast.Node]docBlock)
+	}
+	if c.MaskExampleOutput {
+		c.inOutput = make(map[ast.Node]bool)
+	}
+	if c.MaskDiagrams {
+		c.tableRows = make(map[ast.Node]bool)
+	}
+	if c.MaskSymbolRefs {
+		c.symbolRefs = make(map[ast.Node][]span)
+	}
+	if c.MaxTokenSize < 1 {
+		return nil, fmt.Errorf("invalid max token size: %d", c.MaxTokenSize)
+	}
+	if c.Severity.ExportedDocs != c.Severity.Other {
+		c.exported = make(map[ast.Node]bool)
+	}
+	if c.CheckSentenceCase || c.CheckDocNames || c.CheckEmbedPatterns || c.CheckConfusables || c.CheckModNotices {
+		c.style = make(map[ast.Node][]finding)
+	}
 
 	// Add optional heuristics.
 	if c.IgnoreUpper {
@@ -96,19 +291,68 @@ func newChecker(d *dictionary, cfg config) (*checker, error) {
 	if c.IgnoreNumbers {
 		c.heuristics = append(c.heuristics, &isNumber{})
 	}
+	if c.IgnoreMixedAlnum {
+		c.heuristics = append(c.heuristics, isMixedAlnum{})
+	}
 	if len(c.Patterns) != 0 {
-		p, err := newPatterns(c.Patterns)
+		p, err := newPatterns(c.Patterns, c.PatternRules)
 		if err != nil {
 			return nil, err
 		}
 		c.heuristics = append(c.heuristics, p)
 	}
+	heuristics, err := orderHeuristics(c.heuristics, c.HeuristicOrder)
+	if err != nil {
+		return nil, err
+	}
+	c.heuristics = heuristics
+	if c.ProfileHeuristics {
+		c.heuristics = profileHeuristics(c.heuristics)
+	}
+	pathFilters, err := newPathFilters(c.Where)
+	if err != nil {
+		return nil, err
+	}
+	c.pathFilters = pathFilters
+	c.generatedFiles, err = newPathFilter(c.GeneratedFiles)
+	if err != nil {
+		return nil, fmt.Errorf("could not construct generated files pattern: %w", err)
+	}
+	c.stringFocus, err = newStringFocus(c.StringsIn)
+	if err != nil {
+		return nil, fmt.Errorf("could not construct string focus: %w", err)
+	}
+	for _, re := range c.LinePatterns {
+		lp, err := regexp.Compile(re)
+		if err != nil {
+			return nil, fmt.Errorf("could not construct line pattern: %w", err)
+		}
+		c.linePatterns = append(c.linePatterns, lp)
+	}
+	if c.SkipHeaders {
+		for _, re := range c.HeaderPatterns {
+			hp, err := regexp.Compile(re)
+			if err != nil {
+				return nil, fmt.Errorf("could not construct header pattern: %w", err)
+			}
+			c.headerPatterns = append(c.headerPatterns, hp)
+		}
+	}
 	if c.since != "" {
 		new, err := gitAdditionsSince(c.since, c.DiffContext)
 		if err != nil {
 			return nil, err
 		}
 		c.changeFilter = new
+		if c.SuggestNewOnly {
+			c.addedLines = new
+			if c.DiffContext != 0 {
+				c.addedLines, err = gitAdditionsSince(c.since, 0)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
 	}
 
 	return c, nil
@@ -117,63 +361,184 @@ func newChecker(d *dictionary, cfg config) (*checker, error) {
 // check checks the provided text and outputs information about any misspellings
 // in the text.
 func (c *checker) check(text string, node ast.Node) (ok bool) {
-	var misspellings []misspelled
+	c.useDictionary(c.fileset.Position(node.Pos()).Filename)
+
+	var findings []finding
 
 	if c.CheckURLs {
-		misspellings = c.confirmURLtargets(misspellings, text, node)
+		findings = c.confirmURLtargets(findings, text, node)
+	}
+	if c.CheckIssues {
+		findings = c.confirmIssues(findings, text, node)
+	}
+	if c.CheckRFCs {
+		findings = c.confirmRFCs(findings, text, node)
+	}
+	if c.CheckPaperRefs {
+		findings = c.confirmPaperRefs(findings, text, node)
+	}
+	for _, f := range c.style[node] {
+		if !c.changeFilter.isInChange(node.Pos()+token.Pos(f.span.pos), c.fileset) {
+			continue
+		}
+		findings = append(findings, f)
 	}
 
-	sc := bufio.NewScanner(c.textReader(text))
-	w := words{}
-	sc.Split(w.ScanWords)
+	sc := bufio.NewScanner(c.textReader(text, node))
+	sc.Buffer(nil, c.MaxTokenSize)
+	w := newTokenizer(c.Tokenizer, c.MaxTokenSize)
+	sc.Split(w.Split)
 
 	for sc.Scan() {
-		if !c.changeFilter.isInChange(node.Pos()+token.Pos(w.current.pos), c.fileset) {
+		if !c.changeFilter.isInChange(node.Pos()+token.Pos(w.Current().pos), c.fileset) {
 			continue
 		}
 
-		word := sc.Text()
+		// Remove configured suffixes and prefixes
+		// from words, adjusting the word's start
+		// position for any removed prefix.
+		word, off := c.trimAffixes(sc.Text())
+		current := w.Current()
+		current.pos += off
 
-		// Remove common suffixes from words.
-		// Note that prefix removal cannot be
-		// done without adjusting the word's
-		// start position.
-		switch {
-		case strings.HasSuffix(word, "'s"):
-			word = strings.TrimSuffix(word, "'s")
-		case strings.HasSuffix(word, "'d"):
-			word = strings.TrimSuffix(word, "'d")
-		case strings.HasSuffix(word, "'ed"):
-			word = strings.TrimSuffix(word, "'ed")
-		case strings.HasSuffix(word, "'th"):
-			word = strings.TrimSuffix(word, "'th")
+		if c.consistency != nil {
+			pos := c.fileset.Position(node.Pos())
+			pos.Offset += current.pos
+			pos.Column += current.pos
+			c.consistency.note(stripUnderscores(word), pos)
 		}
 
-		ok, note := c.isCorrect(stripUnderscores(word), false)
+		ok, note := c.isCorrect(c.dictionary.condition(stripUnderscores(word)), false)
+		if !ok && c.IgnoreNames && isPersonName(text, current, word) {
+			ok, note = true, "person name heuristic"
+		}
+		if c.stats != nil {
+			c.stats.note(stripUnderscores(word), ok)
+		}
 		if ok {
+			if c.suppressed != nil && note != "" && !c.dictionary.IsCorrect(stripUnderscores(word)) {
+				c.suppressed.note(c.fileset.Position(node.Pos()), current.pos, word, note)
+			}
 			continue
 		}
-		misspellings = append(misspellings, misspelled{
-			word:    word,
-			span:    w.current,
-			note:    note,
-			suggest: true,
+		var confidence float64
+		if c.ShowConfidence || c.MinConfidence > 0 {
+			confidence = c.confidence(stripUnderscores(word), note)
+			if confidence < c.MinConfidence {
+				continue
+			}
+		}
+		findings = append(findings, finding{
+			category:   spellingCategory,
+			word:       word,
+			span:       current,
+			note:       note,
+			confidence: confidence,
 		})
 	}
-	if len(misspellings) != 0 {
-		c.misspellings = append(c.misspellings, misspelling{
-			words: misspellings,
+	c.reportLongWords(w.Long(), node)
+	if err := sc.Err(); err != nil {
+		c.reportScanError(err, w.Current().end, node)
+	}
+	findings = c.lintIgnored(findings, node)
+	if len(findings) != 0 {
+		// Findings from URL and reference checks
+		// precede spelling findings, so put them
+		// in text order for reporting.
+		sort.SliceStable(findings, func(i, j int) bool {
+			return findings[i].span.pos < findings[j].span.pos
+		})
+		level := c.Severity.Other
+		if c.exported[node] {
+			level = c.Severity.ExportedDocs
+		}
+		t := checkedText{
 			where: where(node),
 			text:  text,
 			pos:   c.fileset.Position(node.Pos()),
 			end:   c.fileset.Position(node.End()),
-		})
+		}
+		if level == errorLevel {
+			var n int
+			for _, f := range findings {
+				if f.severity != warningLevel {
+					n++
+				}
+			}
+			if c.generated[t.pos.Filename] {
+				c.generatedFailures += n
+			} else {
+				c.failures += n
+			}
+		}
+		for _, f := range findings {
+			t.findings = append(t.findings, c.locate(f, t, level == warningLevel))
+		}
+		c.checked = append(c.checked, t)
 	}
-	return len(misspellings) == 0
+	return len(findings) == 0
 }
 
-// rel returns the wd-relative path for the input if possible.
+// reportLongWords writes a warning to stderr for each span of text in
+// node that was not checked because it was longer than the maximum token
+// size.
+func (c *checker) reportLongWords(long []span, node ast.Node) {
+	for _, s := range long {
+		c.warnSkipped(s.pos, node, fmt.Sprintf("word of %d bytes in %s not checked (longer than %d bytes)", s.end-s.pos, where(node), c.MaxTokenSize))
+	}
+}
+
+// reportScanError writes a warning to stderr that the text in node was
+// only checked up to offset off because scanning failed with err.
+func (c *checker) reportScanError(err error, off int, node ast.Node) {
+	c.warnSkipped(off, node, fmt.Sprintf("%s only partially checked: %v", where(node), err))
+}
+
+// warnSkipped writes the message for content skipped at offset off in
+// node to stderr, and records it if skipped content is being reported.
+func (c *checker) warnSkipped(off int, node ast.Node, msg string) {
+	pos := c.fileset.Position(node.Pos())
+	if pos.IsValid() {
+		pos.Offset += off
+		pos.Column += off
+		fmt.Fprintf(os.Stderr, "%v:%d:%d: %s\n", rel(pos.Filename), pos.Line, pos.Column, msg)
+	} else {
+		// Match the offsets reported for findings
+		// in embedded files without line information.
+		pos.Offset = off
+		fmt.Fprintf(os.Stderr, "%v@%d: %s\n", rel(pos.Filename), pos.Offset, msg)
+	}
+	if c.skipped != nil {
+		c.skipped.note(pos, msg)
+	}
+}
+
+// relRoots is the set of module roots that reported paths are relative
+// to. If it is nil, reported paths are relative to the working directory.
+var relRoots map[string]bool
+
+// rel returns the path of the input relative to the root of the module
+// holding it if relRoots is set and the input is within one of the roots,
+// and the wd-relative path for the input otherwise.
 func rel(path string) string {
+	if relRoots != nil {
+		for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+			if relRoots[dir] {
+				if rel, err := filepath.Rel(dir, path); err == nil {
+					return rel
+				}
+				break
+			}
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	return wdRel(path)
+}
+
+// wdRel returns the wd-relative path for the input if possible.
+func wdRel(path string) string {
 	wd, err := os.Getwd()
 	if err != nil {
 		return path
@@ -188,13 +553,15 @@ func rel(path string) string {
 // where returns a string representation of the class of syntax
 // component where the misspelling was identified.
 func where(n ast.Node) string {
-	switch n.(type) {
+	switch n := n.(type) {
 	case *ast.Comment:
 		return "comment"
 	case *ast.BasicLit:
 		return "string"
 	case *embedded:
 		return "embedded file"
+	case *textLine:
+		return n.kind
 	default:
 		return fmt.Sprintf("unexpected node type: %T", n)
 	}
@@ -204,8 +571,38 @@ func where(n ast.Node) string {
 // of go help generate.
 var genNote = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
 
-// noteGenerated collects the set of files that have been marked as generated.
-func (c *checker) noteGenerated(f *ast.File) {
+// genSignature matches the notes of code generators that do not use the
+// specified pattern for generated code notes.
+var genSignature = regexp.MustCompile(`(?i)^(?://|/\*)\s*(?:code generated|auto-?generated|(?:this file|this code) (?:was|is) (?:automatically |auto-)?generated)\b|(?-i:\bDO NOT EDIT\b)|@generated\b`)
+
+// generatedSuffixes is the set of file name suffixes used by code
+// generators that may omit generated code notes.
+var generatedSuffixes = []string{
+	".pb.go",          // protoc-gen-go and protoc-gen-go-grpc.
+	".pb.gw.go",       // grpc-gateway.
+	".pb.validate.go", // protoc-gen-validate.
+	".twirp.go",       // twirp.
+}
+
+// noteGenerated collects the set of files that have been marked as generated,
+// either by a generated code note or generator signature before the package
+// clause, by a file name suffix used by code generators, or by matching the
+// configured generated file patterns.
+func (c *checker) noteGenerated(pkg *packages.Package, f *ast.File) {
+	name := c.fileset.Position(f.Pos()).Filename
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			c.generated[name] = true
+			return
+		}
+	}
+	if len(c.generatedFiles.rules) != 0 {
+		root, path, err := absRoot(pkg, name)
+		if err == nil && c.generatedFiles.matches(root, path) {
+			c.generated[name] = true
+			return
+		}
+	}
 	for _, g := range f.Comments {
 		if g.Pos() > f.Package {
 			// From go help generate:
@@ -219,30 +616,177 @@ func (c *checker) noteGenerated(f *ast.File) {
 			return
 		}
 		for _, cm := range g.List {
-			if genNote.MatchString(cm.Text) {
-				c.generated[c.fileset.Position(f.Pos()).Filename] = true
+			if genNote.MatchString(cm.Text) || genSignature.MatchString(cm.Text) {
+				c.generated[name] = true
 				return
 			}
 		}
 	}
 }
 
+// isFileHeader returns whether the comment group g is the header comment
+// of f. The header is the first comment in the file when it precedes the
+// package clause, is not the package documentation and has a line that
+// matches one of the configured header patterns.
+func (c *checker) isFileHeader(f *ast.File, g *ast.CommentGroup) bool {
+	if len(f.Comments) == 0 || g != f.Comments[0] || g == f.Doc || g.Pos() > f.Package {
+		return false
+	}
+	for _, cm := range g.List {
+		for _, line := range strings.Split(cm.Text, "\n") {
+			line = trimCommentMarkers(line)
+			for _, re := range c.headerPatterns {
+				if re.MatchString(line) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 var (
 	// urls is used for masking URLs in check.
 	urls = xurls.Strict()
 
+	// importPaths is used for masking import paths in check. It
+	// matches paths with a domain name first element, for example
+	// golang.org/x/tools/go/packages and github.com/owner/repo/v2,
+	// excluding trailing punctuation.
+	importPaths = regexp.MustCompile(`\b(?:[a-z0-9-]+\.)+[a-z]{2,}(?:/[\w.~+-]*[\w~+-])+`)
+
 	// flags is used for masking flags in check.
 	flags = regexp.MustCompile(`(?:^|\s)(?:-{1,2}\w+)+\b`)
+
+	// placeholders is used for masking usage placeholders in check.
+	// It matches <placeholder>, [placeholder] and PLACEHOLDER...
+	// forms, allowing alternatives separated by a pipe in brackets.
+	placeholders = regexp.MustCompile(`<[A-Za-z][\w.-]*>|\[-{0,2}[A-Za-z][\w.|-]*(?:\.\.\.)?\]|\b[A-Z][A-Z0-9_-]*\.\.\.`)
+
+	// envVars is used for masking environment variable references
+	// in check. It matches $VAR and ${VAR} shell style references.
+	// Windows style %VAR% references are masked by maskWinEnvVars.
+	envVars = regexp.MustCompile(`\$[A-Za-z_]\w*|\$\{[A-Za-z_]\w*\}`)
+
+	// markup is used for masking XML and HTML elements and
+	// character entities in check. Complete tags, including
+	// their attributes, are matched.
+	markup = regexp.MustCompile(`</?[A-Za-z][\w:.-]*(?:\s+[\w:.-]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'<>=]+))?)*\s*/?>|<!--.*?-->|&(?:[A-Za-z][A-Za-z0-9]*|#[0-9]+|#[xX][0-9A-Fa-f]+);`)
 )
 
 // textReader returns an io.Reader containing the provided text conditioned
-// according to the configuration.
-func (c *checker) textReader(text string) io.Reader {
+// according to the configuration and the kind of node holding the text.
+func (c *checker) textReader(text string, node ast.Node) io.Reader {
+	if len(c.linePatterns) != 0 {
+		isIgnored := c.isIgnoredLine
+		if _, ok := node.(*ast.Comment); ok {
+			isIgnored = func(line string) bool {
+				return c.isIgnoredLine(trimCommentMarkers(line))
+			}
+		}
+		if c.suppressed != nil {
+			_, isComment := node.(*ast.Comment)
+			c.suppressed.noteLines(c.fileset.Position(node.Pos()), text, func(line string) string {
+				if isComment {
+					line = trimCommentMarkers(line)
+				}
+				return c.linePattern(line)
+			})
+		}
+		text = maskLines(text, isIgnored)
+	}
+	switch node := node.(type) {
+	case *ast.Comment:
+		text = maskLines(text, isBuildConstraint)
+		text = maskLines(text, isLintDirective)
+		if refs := c.symbolRefs[node]; len(refs) != 0 {
+			b := []byte(text)
+			for _, r := range refs {
+				copy(b[r.pos:r.end], blank(text[r.pos:r.end]))
+			}
+			text = string(b)
+		}
+		if c.MaskFences {
+			text, _ = maskFences(text, c.inFence[node])
+		}
+		if c.MaskDocSyntax {
+			text, _ = maskDocSyntax(text, c.docBlocks[node])
+		}
+		if c.MaskExampleOutput {
+			text, _ = maskExampleOutput(text, c.inOutput[node])
+		}
+		if c.MaskHeaders {
+			// Strip header decoration first so that the
+			// header text is not masked as a diagram.
+			text = maskHeaders(text)
+		}
+		if c.MaskCode {
+			text = maskLines(text, isCommentedCode)
+		}
+		if c.MaskTodo {
+			text = maskLines(text, isTodo)
+		}
+		if c.MaskDiagrams {
+			text = maskLines(text, isDiagram)
+			if c.tableRows[node] {
+				text = blank(text)
+			} else {
+				text = maskAlignedRows(text)
+			}
+		}
+		if c.MaskCitations {
+			text = maskLines(text, isCitation)
+		}
+	case *ast.BasicLit:
+		if c.MaskJSON {
+			text, _ = maskJSON(text)
+		}
+		if c.helpText[node] {
+			// Help text is prose interspersed with
+			// flags and usage placeholders.
+			if !c.MaskPlaceholders {
+				text = placeholders.ReplaceAllStringFunc(text, blank)
+			}
+			if !c.MaskFlags {
+				text = flags.ReplaceAllStringFunc(text, blank)
+			}
+		}
+	case *embedded:
+		if c.CheckCatalogs {
+			text = maskCatalog(node.path, text)
+		}
+		if html, ok := c.templates[node.path]; ok {
+			text = maskTemplate(text, html)
+		}
+	case *textLine:
+		switch node.kind {
+		case changelog:
+			text = maskChangelog(text)
+		case modFile:
+			text = maskModFile(text)
+		}
+	}
 	if c.MaskURLs {
 		text = urls.ReplaceAllStringFunc(text, func(s string) string {
 			return strings.Repeat(" ", len(s))
 		})
 	}
+	if c.MaskPaperRefs {
+		text = paperRefs.ReplaceAllStringFunc(text, blank)
+	}
+	if c.MaskImportPaths {
+		text = importPaths.ReplaceAllStringFunc(text, blank)
+	}
+	if c.MaskMarkup {
+		text = markup.ReplaceAllStringFunc(text, blank)
+	}
+	if c.MaskPlaceholders {
+		text = placeholders.ReplaceAllStringFunc(text, blank)
+	}
+	if c.MaskEnvVars {
+		text = envVars.ReplaceAllStringFunc(text, blank)
+		text = maskWinEnvVars(text)
+	}
 	if c.MaskFlags {
 		text = flags.ReplaceAllStringFunc(text, func(s string) string {
 			// We don't have a \b for boundaries with dash
@@ -255,72 +799,55 @@ func (c *checker) textReader(text string) io.Reader {
 	return strings.NewReader(text)
 }
 
+// isIgnoredLine returns whether line matches any of the configured
+// line patterns.
+func (c *checker) isIgnoredLine(line string) bool {
+	return c.linePattern(line) != ""
+}
+
+// linePattern returns the first configured line pattern matching line,
+// or the empty string if none match.
+func (c *checker) linePattern(line string) string {
+	for _, re := range c.linePatterns {
+		if re.MatchString(line) {
+			return re.String()
+		}
+	}
+	return ""
+}
+
 // confirmURLtargets fills and returns dst with a list of unreachable URL
 // targets with the HTTP status or error reasons included.
-func (c *checker) confirmURLtargets(dst []misspelled, text string, node ast.Node) []misspelled {
-	for _, idx := range urls.FindAllStringIndex(text, -1) {
-		if !c.changeFilter.isInChange(node.Pos()+token.Pos(idx[0]), c.fileset) {
-			continue
-		}
-		u := text[idx[0]:idx[1]]
-		if c.dictionary.ignoredURLs[u] {
-			continue
-		}
-		parsed, err := url.Parse(u)
-		if err != nil {
-			continue
-		}
-		switch parsed.Scheme {
-		case "http", "https":
-		default:
-			continue
-		}
-		// While servers may treat GET and HEAD differently, resulting
-		// in false positives and negatives, use of HEAD is justified by
-		// https://datatracker.ietf.org/doc/html/rfc2616/#section-9.4.
-		//
-		//  This method is often used for testing hypertext links for
-		//  validity, accessibility, and recent modification.
-		//
-		resp, err := http.Head(u)
-		if err != nil {
-			dst = append(dst, misspelled{
-				word: u,
-				span: span{pos: idx[0], end: idx[1]},
-				note: fmt.Sprintf("unreachable (%v)", err),
-			})
-			c.dictionary.noteMisspelling(u)
-			continue
-		}
-		io.Copy(io.Discard, resp.Body)
-		resp.Body.Close()
-		switch statusClass := resp.StatusCode / 100; statusClass {
-		case 4, 5:
-			dst = append(dst, misspelled{
-				word: u,
-				span: span{pos: idx[0], end: idx[1]},
-				note: fmt.Sprintf("unreachable (%v)", resp.Status),
-			})
-			c.dictionary.noteMisspelling(u)
-		}
+func (c *checker) confirmURLtargets(dst []finding, text string, node ast.Node) []finding {
+	unreachable := c.urlTargets.unreachable(text, func(off int) bool {
+		return c.changeFilter.isInChange(node.Pos()+token.Pos(off), c.fileset)
+	})
+	for _, m := range unreachable {
+		c.dictionary.noteMisspelling(m.word)
 	}
-	return dst
+	return append(dst, unreachable...)
 }
 
 // empty is a word suggestion sentinel indicating that previous suggestion
 // has been made.
 var empty = []string{}
 
-// isCorrect performs the word correctness checks for checker.
+// isCorrect performs the word correctness checks for checker. If the word
+// is correct, note holds the reason given by the heuristic that accepted
+// the word or one of its fragments, or is empty if no heuristic was used.
 func (c *checker) isCorrect(word string, partial bool) (ok bool, note string) {
 	for _, h := range c.heuristics {
 		if h.isAcceptable(word, partial) {
-			return true, ""
+			c.notePatternWord(h, word)
+			return true, h.reason(word) + " heuristic"
 		}
 	}
 	if c.dictionary.IsCorrect(word) {
 		return true, ""
 	}
+	if c.dictionary.isIdentifierForm(word) {
+		return true, "identifier form heuristic"
+	}
 	if partial {
 		c.dictionary.noteMisspelling(word)
 		return false, "misspelled"
@@ -333,26 +860,62 @@ func (c *checker) isCorrect(word string, partial bool) (ok bool, note string) {
 	}
 	var fragments []string
 	if c.CamelSplit {
-		// TODO(kortschak): Allow user-configurable
-		// known words for camel case splitting.
 		fragments = c.camel.Split(word)
 	} else {
 		fragments = strings.Split(word, "_")
 	}
+	var reason string
 	for _, frag := range fragments {
-		if ok, _ = c.isCorrect(frag, true); !ok {
+		ok, r := c.isCorrect(frag, true)
+		if !ok {
 			return false, "misspelled"
 		}
+		if reason == "" && r != "" {
+			reason = fmt.Sprintf("%s for fragment %q", r, frag)
+		}
 	}
-	return true, ""
+	return true, reason
 }
 
-// caseFoldMatch returns whether there is a suggestion for the word that
-// is an exact match under case folding. This checks for the common error
-// of failing to adjust export visibility of labels in comments.
+// notePatternWord records the dictionary entry for a word accepted by
+// the heuristic h if h is the patterns heuristic and the word is not
+// already accepted by the dictionary. Only words that the pattern rescues
+// are recorded.
+func (c *checker) notePatternWord(h heuristic, word string) {
+	if c.dictionary.misspelled == nil {
+		return
+	}
+	if ph, ok := h.(*profiledHeuristic); ok {
+		h = ph.heuristic
+	}
+	p, ok := h.(*patterns)
+	if !ok {
+		return
+	}
+	e, ok := p.entry(word)
+	if ok && !c.dictionary.IsCorrect(word) {
+		c.dictionary.noteWord(e)
+	}
+}
+
+// caseFoldMatch returns whether there is a case variant of the word that
+// is correctly spelled. This checks for the common error of failing to
+// adjust export visibility of labels in comments. Only the variants are
+// checked so that suggestions are not sought while checking.
 func (c *checker) caseFoldMatch(word string) bool {
-	for _, suggest := range c.dictionary.Suggest(word) {
-		if strings.EqualFold(suggest, word) {
+	first, n := utf8.DecodeRuneInString(word)
+	rest := word[n:]
+	toggled := unicode.ToUpper(first)
+	if unicode.IsUpper(first) {
+		toggled = unicode.ToLower(first)
+	}
+	for _, v := range []string{
+		strings.ToLower(word),
+		strings.ToUpper(word),
+		string(unicode.ToUpper(first)) + strings.ToLower(rest),
+		string(toggled) + rest,
+	} {
+		if v != word && c.dictionary.IsCorrect(v) {
 			return true
 		}
 	}
@@ -374,6 +937,9 @@ func (c *checker) Visit(n ast.Node) ast.Visitor {
 			}
 		}
 		if c.unexpectedEntropy(text, isDoubleQuoted) {
+			if c.skipped != nil {
+				c.skipped.note(c.fileset.Position(n.Pos()), "string not checked (unexpected entropy)")
+			}
 			return c
 		}
 		c.check(n.Value, n)
@@ -440,9 +1006,30 @@ func expectedEntropy(n, s int) float64 {
 	return -math.Log2(1 / float64(n))
 }
 
+// trimAffixes returns word with the first matching configured suffix and
+// then the first matching configured prefix removed, and the length of the
+// removed prefix. Prefixes are matched without regard to case. Affixes are
+// only removed if some of the word remains.
+func (c *checker) trimAffixes(word string) (string, int) {
+	for _, suffix := range c.TrimSuffixes {
+		if len(word) > len(suffix) && strings.HasSuffix(word, suffix) {
+			word = word[:len(word)-len(suffix)]
+			break
+		}
+	}
+	for _, prefix := range c.TrimPrefixes {
+		if len(word) > len(prefix) && strings.EqualFold(word[:len(prefix)], prefix) {
+			return word[len(prefix):], len(prefix)
+		}
+	}
+	return word, 0
+}
+
 // stripUnderscores removes leading and trailing underscores from
 // words to prevent emph marking used in comments from preventing
 // spell check matching.
+// Other emphasis markers, such as *emph* and **strong**, and trailing
+// colons are punctuation, so they are already removed by the tokenizer.
 func stripUnderscores(s string) string {
 	return strings.TrimFunc(s, func(r rune) bool { return r == '_' })
 }
@@ -0,0 +1,68 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// cliHelpFields is the set of help text fields of CLI framework types
+// keyed by the qualified name of the type.
+var cliHelpFields = map[string]map[string]bool{
+	"github.com/spf13/cobra.Command": {"Short": true, "Long": true, "Example": true},
+
+	"github.com/urfave/cli.App":        {"Usage": true, "UsageText": true, "Description": true},
+	"github.com/urfave/cli.Command":    {"Usage": true, "UsageText": true, "Description": true},
+	"github.com/urfave/cli/v2.App":     {"Usage": true, "UsageText": true, "Description": true},
+	"github.com/urfave/cli/v2.Command": {"Usage": true, "UsageText": true, "Description": true},
+	"github.com/urfave/cli/v3.Command": {"Usage": true, "UsageText": true, "Description": true},
+}
+
+// cliHelpText returns the string literals in help text fields of cobra
+// and urfave/cli command composite literals in f.
+func cliHelpText(p *packages.Package, f *ast.File) []*ast.BasicLit {
+	var help []*ast.BasicLit
+	ast.Inspect(f, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		typ := p.TypesInfo.TypeOf(lit)
+		if ptr, ok := typ.(*types.Pointer); ok {
+			// Elided &T in slices of *T.
+			typ = ptr.Elem()
+		}
+		named, ok := typ.(*types.Named)
+		if !ok || named.Obj().Pkg() == nil {
+			return true
+		}
+		fields := cliHelpFields[named.Obj().Pkg().Path()+"."+named.Obj().Name()]
+		if fields == nil {
+			return true
+		}
+		for _, e := range lit.Elts {
+			kv, ok := e.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok || !fields[key.Name] {
+				continue
+			}
+			ast.Inspect(kv.Value, func(n ast.Node) bool {
+				if s, ok := n.(*ast.BasicLit); ok && s.Kind == token.STRING {
+					help = append(help, s)
+				}
+				return true
+			})
+		}
+		return true
+	})
+	return help
+}
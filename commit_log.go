@@ -0,0 +1,51 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/sys/execabs"
+)
+
+// trailer matches git commit message trailers such as "Signed-off-by:"
+// and "Change-Id:".
+var trailer = regexp.MustCompile(`^[A-Z][a-z]*(?:-[A-Za-z]+)+:\s`)
+
+// gitCommitLines returns the lines of the commit messages in the current
+// git repo in the specified range. If ref is not a range, the commits
+// since ref are returned. Blank lines and trailer lines are omitted.
+func gitCommitLines(ref string) ([]*textLine, error) {
+	if !strings.Contains(ref, "..") {
+		ref += "..HEAD"
+	}
+	gitLog := execabs.Command("git", "log", "--format=%h%x00%B%x00", ref)
+	var buf bytes.Buffer
+	gitLog.Stdout = &buf
+	err := gitLog.Run()
+	if err != nil {
+		return nil, err
+	}
+	return commitLines(buf.String()), nil
+}
+
+// commitLines returns the lines of the commit messages in log, which
+// holds NUL-separated pairs of abbreviated commit hash and message.
+func commitLines(log string) []*textLine {
+	var lines []*textLine
+	fields := strings.Split(log, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		hash := strings.TrimSpace(fields[i])
+		for _, l := range textLines(hash, commitMessage, fields[i+1]) {
+			if trailer.MatchString(l.text) {
+				continue
+			}
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,98 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// confidence returns a score in (0, 1] indicating how confident the checker
+// is that word, which was not accepted and was given the provided note, is
+// misspelled. The score is reduced for words that nearly satisfied one of
+// the heuristics, words that were only rejected after camel case splitting,
+// case mismatches and words without close suggestions.
+func (c *checker) confidence(word, note string) float64 {
+	score := 1.0
+	if strings.Contains(note, "case mismatch") {
+		score *= 0.5
+	}
+	if c.CamelSplit && len(c.camel.Split(word)) > 1 {
+		score *= 0.8
+	}
+
+	var digits, upper, hex int
+	n := utf8.RuneCountInString(word)
+	for _, r := range word {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+		if unicode.IsUpper(r) {
+			upper++
+		}
+		if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			hex++
+		}
+	}
+	switch {
+	case hex == n && digits != 0:
+		// Nearly a naked hex number.
+		score *= 0.5
+	case digits != 0:
+		// Mixed letters and digits.
+		score *= 0.6
+	}
+	if upper == n-digits && n > 1 {
+		// Nearly an all-uppercase word.
+		score *= 0.7
+	}
+	if c.MaxWordLen > 0 && 4*n > 3*c.MaxWordLen {
+		// Nearly longer than the maximum word length.
+		score *= 0.8
+	}
+
+	suggestions := c.dictionarySuggest(word)
+	if len(suggestions) == 0 {
+		return score * 0.6
+	}
+	dist := -1
+	for _, s := range suggestions {
+		d := editDistance(strings.ToLower(word), strings.ToLower(s))
+		if dist < 0 || d < dist {
+			dist = d
+		}
+	}
+	switch {
+	case dist <= 1:
+	case dist == 2:
+		score *= 0.9
+	default:
+		score *= 0.7
+	}
+	return score
+}
+
+// editDistance returns the Levenshtein distance between a and b in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
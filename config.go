@@ -5,6 +5,7 @@
 package main
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -21,63 +22,220 @@ const (
 	success       = 0
 	internalError = 1 << (iota - 1)
 	invocationError
-	directiveError // Currently unused. This will be for linting directives.
+	directiveError // Linter directives are malformed or unused.
 	spellingError
+	generatedSpellingError // Only generated files have error level findings.
 )
 
 // config holds application-wide user configuration values.
 type config struct {
-	IgnoreIdents    bool          `toml:"ignore_idents"`  // ignore words matching identifiers.
-	Lang            string        `toml:"lang"`           // language to use.
-	Show            bool          `toml:"show"`           // show the context of a misspelling.
-	CheckStrings    bool          `toml:"check_strings"`  // check string literals as well as comments.
-	CheckEmbedded   bool          `toml:"check_embedded"` // check spelling in embedded files as well as comments.
-	IgnoreUpper     bool          `toml:"ignore_upper"`   // ignore words that are all uppercase.
-	IgnoreSingle    bool          `toml:"ignore_single"`  // ignore words that are a single rune.
-	IgnoreNumbers   bool          `toml:"ignore_numbers"` // ignore Go syntax number literals.
-	ReadLicenses    bool          `toml:"read_licenses"`  // ignore all words found in license files.
-	GitLog          bool          `toml:"read_git_log"`   // ignore all author names and emails found in git log.
-	MaskFlags       bool          `toml:"mask_flags"`     // ignore words with a leading dash.
-	MaskURLs        bool          `toml:"mask_urls"`      // mask URLs before checking.
-	CheckURLs       bool          `toml:"check_urls"`     // check URLs point to reachable targets.
-	CamelSplit      bool          `toml:"camel"`          // split words on camelCase when retrying.
-	MaxWordLen      int           `toml:"max_word_len"`   // ignore words longer than this.
-	MinNakedHex     int           `toml:"min_naked_hex"`  // ignore words at least this long if only hex digits.
-	Patterns        []string      `toml:"patterns"`       // acceptable words defined by regexp.
-	MakeSuggestions suggest       `toml:"suggest"`        // make suggestions for misspelled words.
-	DiffContext     int           `toml:"diff_context"`   // specify number of lines of change context to include.
-	EntropyFiler    entropyFilter `toml:"entropy_filter"` // specify entropy filter behaviour (experimental).
-
-	since  string
-	words  string
-	paths  string
-	update bool
+	IgnoreIdents         bool              `toml:"ignore_idents"`          // ignore words matching identifiers.
+	Lang                 string            `toml:"lang"`                   // comma-separated list of languages to use.
+	Engine               spellEngine       `toml:"engine"`                 // spelling engine to use.
+	EngineCommand        []string          `toml:"engine_command"`         // command and arguments of the pipe spelling engine.
+	Tokenizer            tokenizerKind     `toml:"tokenizer"`              // word tokenizer to use.
+	TrimSuffixes         []string          `toml:"trim_suffixes"`          // suffixes removed from words before checking.
+	TrimPrefixes         []string          `toml:"trim_prefixes"`          // prefixes removed from words before checking.
+	CacheDict            bool              `toml:"cache_dict"`             // cache the merged base dictionary between runs.
+	ModuleDictionaries   bool              `toml:"module_dicts"`           // use a separate dictionary for each module.
+	PackageWords         bool              `toml:"package_words"`          // use .words and affix files in package directories.
+	Show                 bool              `toml:"show"`                   // show the context of a misspelling.
+	Format               outputFormat      `toml:"format"`                 // specify the output format of findings.
+	CheckStrings         bool              `toml:"check_strings"`          // check string literals as well as comments.
+	CheckFlagUsage       bool              `toml:"check_flag_usage"`       // check flag usage strings even when string literals are not checked.
+	CheckCLIHelp         bool              `toml:"check_cli_help"`         // check cobra and urfave/cli help text even when string literals are not checked.
+	CheckEmbedded        bool              `toml:"check_embedded"`         // check spelling in embedded files as well as comments.
+	CheckChangelogs      bool              `toml:"check_changelogs"`       // check spelling in changelog and release notes files.
+	CheckModFiles        bool              `toml:"check_mod_files"`        // check spelling in go.mod and go.work comments.
+	CheckModNotices      bool              `toml:"check_mod_notices"`      // check module deprecation messages and retraction rationales with the mod-notice rule.
+	CheckCatalogs        bool              `toml:"check_catalogs"`         // check only source language messages in message catalog files.
+	CheckTemplates       bool              `toml:"check_templates"`        // check only literal text in Go template files.
+	CheckTemplateSources bool              `toml:"check_template_sources"` // check all Go template files in package directories.
+	IgnoreUpper          bool              `toml:"ignore_upper"`           // ignore words that are all uppercase.
+	IgnoreSingle         bool              `toml:"ignore_single"`          // ignore words that are a single rune.
+	IgnoreNumbers        bool              `toml:"ignore_numbers"`         // ignore Go syntax number literals.
+	IgnoreMixedAlnum     bool              `toml:"ignore_mixed_alnum"`     // ignore words that mix letters and digits.
+	IgnoreNames          bool              `toml:"ignore_names"`           // ignore capitalised words where names of people are expected.
+	SkipHeaders          bool              `toml:"skip_headers"`           // ignore file header comments such as copyright notices.
+	ReadLicenses         bool              `toml:"read_licenses"`          // ignore all words found in license files.
+	GitLog               bool              `toml:"read_git_log"`           // ignore all author names and emails found in git log.
+	ConditionInput       bool              `toml:"condition_input"`        // normalise typographic characters in words before checking.
+	MaskFlags            bool              `toml:"mask_flags"`             // ignore words with a leading dash.
+	MaskPlaceholders     bool              `toml:"mask_placeholders"`      // ignore usage placeholders such as <file> and [options].
+	MaskURLs             bool              `toml:"mask_urls"`              // mask URLs before checking.
+	MaskImportPaths      bool              `toml:"mask_import_paths"`      // mask import paths before checking.
+	MaskPaperRefs        bool              `toml:"mask_paper_refs"`        // mask DOI and arXiv identifier references before checking.
+	MaskSymbolRefs       bool              `toml:"mask_symbol_refs"`       // mask dotted references to known symbols in comments.
+	MaskCode             bool              `toml:"mask_code"`              // mask comment lines that are commented-out code.
+	MaskTodo             bool              `toml:"mask_todo"`              // mask comment lines starting with TODO, FIXME, HACK or XXX markers.
+	MaskDiagrams         bool              `toml:"mask_diagrams"`          // mask comment lines that are ASCII-art diagrams or table rows.
+	MaskCitations        bool              `toml:"mask_citations"`         // mask comment lines that are bibliographic citations.
+	MaskFences           bool              `toml:"mask_fences"`            // mask the contents of fenced code blocks in comments.
+	MaskDocSyntax        bool              `toml:"mask_doc_syntax"`        // mask doc comment code blocks, link definitions, code spans and link targets.
+	MaskExampleOutput    bool              `toml:"mask_example_output"`    // mask example Output: sections in comments.
+	MaskHeaders          bool              `toml:"mask_headers"`           // mask punctuation decorating comment section headers.
+	MaskEnvVars          bool              `toml:"mask_env_vars"`          // mask environment variable references before checking.
+	MaskMarkup           bool              `toml:"mask_markup"`            // mask XML/HTML tags and entities before checking.
+	MaskJSON             bool              `toml:"mask_json"`              // check only values in string literals holding JSON.
+	CheckURLs            bool              `toml:"check_urls"`             // check URLs point to reachable targets.
+	CheckIssues          bool              `toml:"check_issues"`           // check issue references point to existing issues.
+	CheckRFCs            bool              `toml:"check_rfcs"`             // check RFC references against the bundled RFC index.
+	CheckPaperRefs       bool              `toml:"check_paper_refs"`       // check DOI and arXiv identifier references exist.
+	CheckSentenceCase    bool              `toml:"check_sentence_case"`    // check doc comment sentences start with a capital or an identifier.
+	CheckDocNames        bool              `toml:"check_doc_names"`        // check exported doc comments start with the declared name.
+	CheckEmbedPatterns   bool              `toml:"check_embed_patterns"`   // check //go:embed patterns match files and do not include large binary files.
+	CheckIgnoredFiles    bool              `toml:"check_ignored_files"`    // check Go files excluded by build constraints.
+	CheckConfusables     bool              `toml:"check_confusables"`      // report valid words that are likely typos in context (experimental).
+	CheckConsistency     bool              `toml:"check_consistency"`      // report words spelled with inconsistent regional variants.
+	CamelSplit           bool              `toml:"camel"`                  // split words on camelCase when retrying.
+	MinWordLen           int               `toml:"min_word_len"`           // ignore words shorter than this.
+	MaxWordLen           int               `toml:"max_word_len"`           // ignore words longer than this.
+	MinNakedHex          int               `toml:"min_naked_hex"`          // ignore words at least this long if only hex digits.
+	MaxTokenSize         int               `toml:"max_token_size"`         // maximum length in bytes of a word held by the word scanner.
+	Patterns             []string          `toml:"patterns"`               // acceptable words defined by regexp.
+	PatternRules         map[string]string `toml:"pattern_rules"`          // affix rules for recording words accepted by patterns.
+	LinePatterns         []string          `toml:"line_patterns"`          // lines to ignore defined by regexp.
+	HeaderPatterns       []string          `toml:"header_patterns"`        // file header comments to ignore defined by regexp.
+	GeneratedFiles       []string          `toml:"generated_files"`        // additional generated file patterns.
+	StringsIn            []string          `toml:"check_strings_in"`       // struct fields and function arguments whose string literals are always checked.
+	Initialisms          []string          `toml:"initialisms"`            // mixed-case initialisms accepted and used for camel case splitting.
+	HeuristicOrder       []string          `toml:"heuristic_order"`        // order in which heuristics are run.
+	MakeSuggestions      suggest           `toml:"suggest"`                // make suggestions for misspelled words.
+	MaxSuggestDistance   int               `toml:"max_suggest_distance"`   // maximum edit distance of suggestions from misspelled words.
+	SuggestTimeout       int               `toml:"suggest_timeout"`        // maximum time in milliseconds to find suggestions for a word.
+	SuggestNewOnly       bool              `toml:"suggest_new_only"`       // only make suggestions for findings on lines added since the since ref.
+	ShowConfidence       bool              `toml:"show_confidence"`        // show the confidence that findings are misspellings.
+	ShowSuppressed       bool              `toml:"show_suppressed"`        // show words and lines suppressed by heuristics and line patterns.
+	ProfileHeuristics    bool              `toml:"profile_heuristics"`     // report the use and cost of each heuristic.
+	ReportHarvest        bool              `toml:"report_harvest"`         // report the number of words added from each harvest source.
+	DedupeComments       bool              `toml:"dedupe_comments"`        // report findings in repeated identical comments once.
+	ModuleRelative       bool              `toml:"module_relative"`        // report paths relative to the module root.
+	ReportSkipped        bool              `toml:"report_skipped"`         // report content that was not checked or was only partially checked.
+	MinConfidence        float64           `toml:"min_confidence"`         // only report misspellings with at least this confidence.
+	DiffContext          int               `toml:"diff_context"`           // specify number of lines of change context to include.
+	Severity             severity          `toml:"severity"`               // specify severity of findings by where they are found.
+	Harvest              harvestSet        `toml:"harvest"`                // specify sources of words harvested from source code.
+	Where                []whereFilter     `toml:"where"`                  // specify kinds of text to check by path.
+	EntropyFiler         entropyFilter     `toml:"entropy_filter"`         // specify entropy filter behaviour (experimental).
+
+	since       string
+	files       string
+	words       string
+	paths       string
+	update      bool
+	decisions   string
+	commit      bool
+	commitMsg   string
+	prompt      bool
+	interactive bool
 }
 
 var defaults = config{
 	// Dictionary options.
-	IgnoreIdents: true,
-	Lang:         "en_US",
+	IgnoreIdents:       true,
+	Lang:               "en_US",
+	Engine:             hunspellEngine,
+	EngineCommand:      []string{"hunspell", "-a", "-d", "{lang}"},
+	Tokenizer:          wordsTokenizer,
+	TrimSuffixes:       []string{"'s", "'d", "'ed", "'th", "’s", "’d", "’ed", "’th"},
+	TrimPrefixes:       nil,
+	CacheDict:          false,
+	ModuleDictionaries: false,
+	PackageWords:       false,
 
 	paths: path,
 
 	// Checker options.
-	Show:            true,
-	CheckStrings:    false,
-	CheckEmbedded:   false,
-	IgnoreUpper:     true,
-	IgnoreSingle:    true,
-	IgnoreNumbers:   true,
-	ReadLicenses:    true,
-	GitLog:          true,
-	MaskFlags:       false,
-	MaskURLs:        true,
-	CheckURLs:       false,
-	CamelSplit:      true,
-	MaxWordLen:      40,
-	MinNakedHex:     8,
-	MakeSuggestions: never,
-	DiffContext:     0,
+	Show:                 true,
+	Format:               textFormat,
+	CheckStrings:         false,
+	CheckFlagUsage:       true,
+	CheckCLIHelp:         false,
+	CheckEmbedded:        false,
+	CheckChangelogs:      false,
+	CheckModFiles:        false,
+	CheckModNotices:      false,
+	CheckCatalogs:        false,
+	CheckTemplates:       false,
+	CheckTemplateSources: false,
+	IgnoreUpper:          true,
+	IgnoreSingle:         true,
+	IgnoreNumbers:        true,
+	IgnoreMixedAlnum:     false,
+	IgnoreNames:          false,
+	SkipHeaders:          false,
+	ReadLicenses:         true,
+	GitLog:               true,
+	ConditionInput:       true,
+	MaskFlags:            false,
+	MaskPlaceholders:     false,
+	MaskURLs:             true,
+	MaskImportPaths:      false,
+	MaskPaperRefs:        true,
+	MaskSymbolRefs:       false,
+	MaskCode:             false,
+	MaskTodo:             false,
+	MaskDiagrams:         false,
+	MaskCitations:        false,
+	MaskFences:           false,
+	MaskDocSyntax:        false,
+	MaskExampleOutput:    false,
+	MaskHeaders:          false,
+	MaskEnvVars:          false,
+	MaskMarkup:           false,
+	MaskJSON:             true,
+	CheckURLs:            false,
+	CheckIssues:          false,
+	CheckRFCs:            false,
+	CheckPaperRefs:       false,
+	CheckSentenceCase:    false,
+	CheckDocNames:        false,
+	CheckEmbedPatterns:   false,
+	CheckIgnoredFiles:    false,
+	CheckConfusables:     false,
+	CheckConsistency:     false,
+	CamelSplit:           true,
+	MinWordLen:           0,
+	MaxWordLen:           40,
+	MinNakedHex:          8,
+	MaxTokenSize:         bufio.MaxScanTokenSize,
+	Initialisms: []string{
+		"GiB", "KiB", "MiB", "PiB", "TiB",
+		"IPv4", "IPv6",
+		"OAuth",
+		"gRPC",
+		"iOS", "macOS",
+		"mTLS",
+	},
+	HeaderPatterns: []string{
+		`(?i)\bcopyright\b`,
+		`\bSPDX-License-Identifier:`,
+	},
+	MakeSuggestions:    never,
+	MaxSuggestDistance: 0,
+	SuggestTimeout:     0,
+	SuggestNewOnly:     false,
+	ShowConfidence:     false,
+	ShowSuppressed:     false,
+	ProfileHeuristics:  false,
+	ReportHarvest:      false,
+	DedupeComments:     false,
+	ModuleRelative:     false,
+	MinConfidence:      0,
+	DiffContext:        0,
+	Severity: severity{
+		ExportedDocs: errorLevel,
+		Other:        errorLevel,
+	},
+	Harvest: harvestSet{
+		Identifiers:     true,
+		TagKeys:         true,
+		TagValues:       true,
+		Directives:      true,
+		PackagePaths:    true,
+		ImportedSymbols: false,
+		NoteAuthors:     true,
+	},
 
 	// Experimental options.
 	EntropyFiler: entropyFilter{
@@ -111,6 +269,175 @@ func (s *suggest) Set(val string) error {
 	return fmt.Errorf(`valid options are "never", "once", "each" and "always"`)
 }
 
+// Output formats.
+const (
+	textFormat outputFormat = iota
+	jsonFormat
+)
+
+var formatNames = []string{textFormat: "text", jsonFormat: "json"}
+
+type outputFormat int
+
+func (f outputFormat) String() string {
+	if f < 0 || int(f) >= len(formatNames) {
+		return fmt.Sprintf("format(%d)", int(f))
+	}
+	return formatNames[f]
+}
+
+func (f outputFormat) MarshalText() ([]byte, error)  { return []byte(f.String()), nil }
+func (f *outputFormat) UnmarshalText(b []byte) error { return f.Set(string(b)) }
+
+func (f *outputFormat) Set(val string) error {
+	for i, name := range formatNames {
+		if val == name {
+			*f = outputFormat(i)
+			return nil
+		}
+	}
+	return fmt.Errorf(`valid options are "text" and "json"`)
+}
+
+// Spelling engines.
+const (
+	hunspellEngine spellEngine = iota
+	builtinEngine
+	pipeEngine
+)
+
+var engineNames = []string{hunspellEngine: "hunspell", builtinEngine: "builtin", pipeEngine: "pipe"}
+
+type spellEngine int
+
+func (e spellEngine) String() string {
+	if e < 0 || int(e) >= len(engineNames) {
+		return fmt.Sprintf("engine(%d)", int(e))
+	}
+	return engineNames[e]
+}
+
+func (e spellEngine) MarshalText() ([]byte, error)  { return []byte(e.String()), nil }
+func (e *spellEngine) UnmarshalText(b []byte) error { return e.Set(string(b)) }
+
+func (e *spellEngine) Set(val string) error {
+	for i, name := range engineNames {
+		if val == name {
+			*e = spellEngine(i)
+			return nil
+		}
+	}
+	return fmt.Errorf(`valid options are "hunspell", "builtin" and "pipe"`)
+}
+
+// Word tokenizers.
+const (
+	wordsTokenizer tokenizerKind = iota
+	compoundsTokenizer
+)
+
+var tokenizerNames = []string{wordsTokenizer: "words", compoundsTokenizer: "compounds"}
+
+type tokenizerKind int
+
+func (k tokenizerKind) String() string {
+	if k < 0 || int(k) >= len(tokenizerNames) {
+		return fmt.Sprintf("tokenizer(%d)", int(k))
+	}
+	return tokenizerNames[k]
+}
+
+func (k tokenizerKind) MarshalText() ([]byte, error)  { return []byte(k.String()), nil }
+func (k *tokenizerKind) UnmarshalText(b []byte) error { return k.Set(string(b)) }
+
+func (k *tokenizerKind) Set(val string) error {
+	for i, name := range tokenizerNames {
+		if val == name {
+			*k = tokenizerKind(i)
+			return nil
+		}
+	}
+	return fmt.Errorf(`valid options are "words" and "compounds"`)
+}
+
+// severity specifies the severity of findings based on where they are
+// found.
+type severity struct {
+	// ExportedDocs is the severity of findings
+	// in package doc comments and the doc
+	// comments of exported declarations.
+	ExportedDocs level `toml:"exported_docs"`
+
+	// Other is the severity of all other
+	// findings.
+	Other level `toml:"other"`
+}
+
+// Finding severity levels. Only findings at the error level result in a
+// non-zero exit status.
+const (
+	errorLevel level = iota
+	warningLevel
+)
+
+var levelNames = []string{errorLevel: "error", warningLevel: "warning"}
+
+type level int
+
+func (l level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+func (l level) MarshalText() ([]byte, error)  { return []byte(l.String()), nil }
+func (l *level) UnmarshalText(b []byte) error { return l.Set(string(b)) }
+
+func (l *level) Set(val string) error {
+	for i, name := range levelNames {
+		if val == name {
+			*l = level(i)
+			return nil
+		}
+	}
+	return fmt.Errorf(`valid options are "error" and "warning"`)
+}
+
+// whereFilter specifies the kinds of text to check in files matching a
+// set of path patterns. Kinds that are not specified are not altered by
+// the filter.
+type whereFilter struct {
+	// Paths is the set of path patterns
+	// using .gospelignore semantics.
+	Paths []string `toml:"paths"`
+
+	Comments *bool `toml:"comments"`
+	Strings  *bool `toml:"strings"`
+	Embedded *bool `toml:"embedded"`
+}
+
+// harvestSet specifies the sources of words in the source code that are
+// added to the dictionary. Sources other than note authors are only used
+// when IgnoreIdents is true.
+type harvestSet struct {
+	Identifiers  bool `toml:"identifiers"`
+	TagKeys      bool `toml:"tag_keys"`
+	TagValues    bool `toml:"tag_values"`
+	Directives   bool `toml:"directives"`
+	PackagePaths bool `toml:"package_paths"`
+
+	// ImportedSymbols is whether the exported
+	// symbols of directly imported packages are
+	// added, with plural forms for type names.
+	ImportedSymbols bool `toml:"imported_symbols"`
+
+	// NoteAuthors is whether the user IDs
+	// of notes such as "BUG(uid): ..." are
+	// added.
+	NoteAuthors bool `toml:"note_authors"`
+}
+
 // entropyFilter specifies behaviour of the entropy filter.
 type entropyFilter struct {
 	Filter bool `toml:"filter"`
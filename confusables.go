@@ -0,0 +1,172 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"strings"
+)
+
+// confusedWord is the rule for correctly spelled words that are likely to
+// be typos of another word in their context.
+const confusedWord = "confused-word"
+
+// confusable describes the word that is commonly intended when a correctly
+// spelled word is typed, and the bigram contexts that are evidence for and
+// against the typed word being a typo. Contexts are written "word _" for
+// a previous word and "_ word" for a next word. The typed word is reported
+// when the contexts it is found in hold more evidence for a typo than
+// against it. If there are no contexts, the word is always reported.
+type confusable struct {
+	want       string
+	typo, keep map[string]bool
+}
+
+// contexts returns a set holding the provided bigram contexts.
+func contexts(ctx ...string) map[string]bool {
+	s := make(map[string]bool, len(ctx))
+	for _, c := range ctx {
+		s[c] = true
+	}
+	return s
+}
+
+// isTypo returns whether the word described by c is likely to be a typo
+// when it follows prev and precedes next. Either of prev and next may be
+// empty if there is no adjacent word.
+func (c confusable) isTypo(prev, next string) bool {
+	if c.typo == nil && c.keep == nil {
+		return true
+	}
+	var score int
+	for _, ctx := range []string{prev + " _", "_ " + next} {
+		if strings.HasPrefix(ctx, " ") || strings.HasSuffix(ctx, " ") {
+			continue
+		}
+		if c.typo[ctx] {
+			score++
+		}
+		if c.keep[ctx] {
+			score--
+		}
+	}
+	return score > 0
+}
+
+// confusables is the table of commonly confused words checked by the
+// confused-word rule, keyed by the confused word. The rule only knows the
+// words in the table, and only the words immediately before and after a
+// word are used as its context. The contexts are chosen to make false
+// positives unlikely rather than to find all typos.
+var confusables = map[string]confusable{
+	"form": {
+		want: "from",
+		typo: contexts("read _", "returned _", "removed _", "taken _", "derived _", "obtained _", "copied _",
+			"loaded _", "received _", "away _", "apart _", "different _", "differs _", "separate _", "come _",
+			"comes _", "came _", "far _", "except _",
+			"_ the", "_ this", "_ that", "_ these", "_ those", "_ each", "_ which", "_ any", "_ its"),
+		keep: contexts("to _", "will _", "can _", "may _", "must _", "should _", "would _", "could _",
+			"they _", "we _", "together _", "a _", "the _", "this _", "that _", "short _",
+			"_ of", "_ part"),
+	},
+	"then": {
+		want: "than",
+		typo: contexts("more _", "less _", "fewer _", "greater _", "larger _", "smaller _", "rather _",
+			"other _", "better _", "worse _", "longer _", "shorter _", "higher _", "lower _", "faster _",
+			"slower _"),
+	},
+	"its": {
+		want: "it's",
+		typo: contexts("_ not", "_ a", "_ an", "_ the", "_ been", "_ possible", "_ important", "_ safe",
+			"_ necessary", "_ likely"),
+	},
+	"it's": {
+		want: "its",
+		typo: contexts("_ own", "_ value", "_ length", "_ contents", "_ type", "_ name", "_ children",
+			"_ parent", "_ argument", "_ arguments", "_ receiver", "_ fields", "_ size", "_ elements"),
+	},
+	"loose": {
+		want: "lose",
+		typo: contexts("will _", "to _", "may _", "might _", "could _", "would _", "not _", "can _"),
+	},
+	"fro": {
+		want: "for",
+		typo: contexts("_ the", "_ a", "_ an", "_ each", "_ all", "_ example", "_ this", "_ that"),
+		keep: contexts("to _"),
+	},
+	"weather": {
+		want: "whether",
+		typo: contexts("_ or", "_ it", "_ we", "_ to", "_ this", "_ they"),
+	},
+	"wether":  {want: "whether"},
+	"pubic":   {want: "public"},
+	"retuned": {want: "returned"},
+}
+
+// proseWord matches words, including contractions, in comment text.
+var proseWord = regexp.MustCompile(`[\pL]+(?:'[\pL]+)?`)
+
+// confusedWords returns the words in the comment group g that are likely
+// to be typos of another word in their context. Findings are keyed by the
+// comment holding them, and their spans are relative to the comment's
+// text. Code blocks and directives are skipped.
+func confusedWords(g *ast.CommentGroup) map[*ast.Comment][]finding {
+	var found map[*ast.Comment][]finding
+	for _, cm := range g.List {
+		if strings.HasPrefix(cm.Text, "//go:") || strings.HasPrefix(cm.Text, "//line ") || isLintDirective(cm.Text) {
+			continue
+		}
+		var off int
+		for _, line := range strings.SplitAfter(cm.Text, "\n") {
+			lineOff := off
+			off += len(line)
+
+			content, pos := docContent(line)
+			if strings.HasPrefix(content, "\t") || strings.HasPrefix(content, " ") {
+				// Code blocks are not prose.
+				continue
+			}
+			idx := proseWord.FindAllStringIndex(content, -1)
+			for i, w := range idx {
+				word := content[w[0]:w[1]]
+				c, ok := confusables[word]
+				if !ok {
+					continue
+				}
+				var prev, next string
+				if i != 0 && isSpaceOnly(content[idx[i-1][1]:w[0]]) {
+					prev = strings.ToLower(content[idx[i-1][0]:idx[i-1][1]])
+				}
+				if i+1 < len(idx) && isSpaceOnly(content[w[1]:idx[i+1][0]]) {
+					next = strings.ToLower(content[idx[i+1][0]:idx[i+1][1]])
+				}
+				if !c.isTypo(prev, next) {
+					continue
+				}
+				if found == nil {
+					found = make(map[*ast.Comment][]finding)
+				}
+				p := lineOff + pos + w[0]
+				found[cm] = append(found[cm], finding{
+					category: styleCategory,
+					word:     word,
+					span:     span{pos: p, end: p + len(word)},
+					note:     fmt.Sprintf("possibly a typo for %q", c.want),
+					rule:     confusedWord,
+					severity: warningLevel,
+				})
+			}
+		}
+	}
+	return found
+}
+
+// isSpaceOnly returns whether s is not empty and holds only spaces and
+// tabs, so that the words either side of s are adjacent in a phrase.
+func isSpaceOnly(s string) bool {
+	return s != "" && strings.Trim(s, " \t") == ""
+}
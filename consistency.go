@@ -0,0 +1,146 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// consistency records the spelling variants of words used in a project.
+type consistency struct {
+	// forms holds the positions of each
+	// variant form of a word keyed by the
+	// canonical form of the word.
+	forms map[string]map[string][]token.Position
+}
+
+// newConsistency returns a new consistency.
+func newConsistency() *consistency {
+	return &consistency{forms: make(map[string]map[string][]token.Position)}
+}
+
+// note records the word at pos.
+func (c *consistency) note(word string, pos token.Position) {
+	form := strings.ToLower(word)
+	if form != word && strings.ToUpper(word[:1])+form[1:] != word {
+		// Only consider lowercase and capitalised words.
+		return
+	}
+	key := variantKey(form)
+	forms, ok := c.forms[key]
+	if !ok {
+		forms = make(map[string][]token.Position)
+		c.forms[key] = forms
+	}
+	forms[form] = append(forms[form], pos)
+}
+
+// report writes a report of inconsistently spelled words to stdout and
+// returns whether any were found.
+func (c *consistency) report() bool {
+	keys := make([]string, 0, len(c.forms))
+	for k, forms := range c.forms {
+		if len(forms) > 1 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		forms := c.forms[k]
+		variants := make([]string, 0, len(forms))
+		for f := range forms {
+			variants = append(variants, f)
+		}
+		sort.Slice(variants, func(i, j int) bool {
+			ni, nj := len(forms[variants[i]]), len(forms[variants[j]])
+			if ni != nj {
+				return ni > nj
+			}
+			return variants[i] < variants[j]
+		})
+		counts := make([]string, len(variants))
+		for i, v := range variants {
+			counts[i] = fmt.Sprintf("%q (%d)", v, len(forms[v]))
+		}
+		fmt.Printf("inconsistent spelling: %s\n", strings.Join(counts, ", "))
+		for _, v := range variants {
+			for _, p := range forms[v] {
+				fmt.Printf("\t%v:%d:%d: %q\n", rel(p.Filename), p.Line, p.Column, v)
+			}
+		}
+	}
+	return len(keys) != 0
+}
+
+// variantSuffixes are the regional suffix variations that are normalised
+// to a canonical form by variantKey. Each is applied only when the stem
+// preceding the suffix is at least minStem bytes long to avoid matching
+// short unrelated words such as "rise" and "four".
+var variantSuffixes = []struct {
+	re      *regexp.Regexp
+	repl    string
+	minStem int
+}{
+	{re: regexp.MustCompile(`^(.+)is(e|es|ed|ing|er|ers|ation|ations)$`), repl: "${1}iz$2", minStem: 3},
+	{re: regexp.MustCompile(`^(.+)ys(e|es|ed|ing|er|ers)$`), repl: "${1}yz$2", minStem: 3},
+	{re: regexp.MustCompile(`^(.+)our(|s|ed|ing|ite|ites|able|ful)$`), repl: "${1}or$2", minStem: 3},
+}
+
+// doubledL is the set of word stems ending in l that double the l before
+// a suffix in some regional spellings.
+var doubledL = map[string]bool{
+	"cancel": true, "channel": true, "counsel": true, "dial": true,
+	"equal": true, "fuel": true, "funnel": true, "label": true,
+	"level": true, "marshal": true, "model": true, "panel": true,
+	"signal": true, "total": true, "travel": true, "tunnel": true,
+	"unmarshal": true,
+}
+
+// doubledLSuffix matches words ending in a doubled l and a suffix.
+var doubledLSuffix = regexp.MustCompile(`^(.+l)l(ed|ing|er|ers)$`)
+
+// variantPairs maps regional word variants that are not handled by rules
+// to a canonical form.
+var variantPairs = map[string]string{
+	"acknowledgement": "acknowledgment", "acknowledgements": "acknowledgments",
+	"aluminium": "aluminum",
+	"analogue":  "analog", "analogues": "analogs",
+	"artefact": "artifact", "artefacts": "artifacts",
+	"catalogue": "catalog", "catalogues": "catalogs",
+	"centre": "center", "centres": "centers", "centred": "centered",
+	"defence":  "defense",
+	"dialogue": "dialog", "dialogues": "dialogs",
+	"fibre": "fiber", "fibres": "fibers",
+	"grey":      "gray",
+	"judgement": "judgment", "judgements": "judgments",
+	"licence": "license", "licences": "licenses",
+	"litre": "liter", "litres": "liters",
+	"metre": "meter", "metres": "meters",
+	"offence":   "offense",
+	"programme": "program", "programmes": "programs",
+}
+
+// variantKey returns a canonical form for word, which must be lowercase.
+// Words with handled regional spelling variants are mapped to a common
+// form. All other words are returned unaltered.
+func variantKey(word string) string {
+	if key, ok := variantPairs[word]; ok {
+		return key
+	}
+	if m := doubledLSuffix.FindStringSubmatch(word); m != nil && doubledL[m[1]] {
+		return m[1] + m[2]
+	}
+	for _, s := range variantSuffixes {
+		m := s.re.FindStringSubmatch(word)
+		if m != nil && len(m[1]) >= s.minStem {
+			return s.re.ReplaceAllString(word, s.repl)
+		}
+	}
+	return word
+}
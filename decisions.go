@@ -0,0 +1,343 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Triage decisions for recorded findings.
+const (
+	undecided    = "?"      // undecided is the decision for findings that have not been triaged.
+	decideFix    = "fix"    // decideFix is the decision to replace the word in the source.
+	decideAdd    = "add"    // decideAdd is the decision to add the word to the module's .words file.
+	decideIgnore = "ignore" // decideIgnore is the decision to leave the finding as it is.
+)
+
+// decisionsHeader is the header written to decisions files.
+const decisionsHeader = `# Mark each finding below with "fix", "add" or "ignore" in place of "?",
+# and then run "gospel words apply <file>" from this directory.
+#
+#  fix    — replace the word with the replacement in the last column,
+#           which may be edited.
+#  add    — add the word to the .words file at the module root.
+#  ignore — make no change.
+#
+# Findings left as "?" are not changed.
+#
+# decision	position	word	replacement
+`
+
+// recordDecisions writes the spelling findings of the checker to the
+// file at path for triage with the words apply command. Each finding
+// is recorded as an undecided line holding the tab-separated decision,
+// working directory-relative position, word and first suggestion for the
+// word.
+func (c *checker) recordDecisions(path string) error {
+	var buf bytes.Buffer
+	buf.WriteString(decisionsHeader)
+	for _, f := range c.findings() {
+		if !f.pos.IsValid() || f.category != spellingCategory {
+			continue
+		}
+		c.useDictionary(f.pos.Filename)
+		var replacement string
+		suggestions, ok := c.suggested[f.word]
+		if !ok || len(suggestions) == 0 {
+			suggestions = c.suggestionsFor(f.word)
+		}
+		if len(suggestions) != 0 {
+			replacement = suggestions[0]
+		}
+		// Positions are relative to the working directory
+		// whether or not reports are module-relative since
+		// the words apply command opens the files from there.
+		pos := fmt.Sprintf("%s:%d:%d", wdRel(f.pos.Filename), f.pos.Line, f.pos.Column)
+		fmt.Fprintf(&buf, "%s\t%s\t%s\t%s\n", undecided, pos, f.word, replacement)
+	}
+	err := os.WriteFile(path, buf.Bytes(), 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write decisions file: %v", err)
+	}
+	return nil
+}
+
+// decision is a triage decision for a finding.
+type decision struct {
+	line int // line is the line of the decision in the decisions file.
+
+	decision    string
+	path        string
+	row, col    int
+	word        string
+	replacement string
+}
+
+// readDecisions returns the decisions in the decisions file at path.
+func readDecisions(path string) ([]decision, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open decisions file: %v", err)
+	}
+	defer f.Close()
+
+	var decisions []decision
+	sc := bufio.NewScanner(f)
+	for i := 1; sc.Scan(); i++ {
+		text := sc.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) < 3 || len(fields) > 4 {
+			return nil, fmt.Errorf("%s:%d: invalid decision line: %q", path, i, text)
+		}
+		d := decision{
+			line:     i,
+			decision: strings.TrimSpace(fields[0]),
+			word:     fields[2],
+		}
+		if len(fields) == 4 {
+			d.replacement = fields[3]
+		}
+		switch d.decision {
+		case undecided, decideFix, decideAdd, decideIgnore:
+		default:
+			return nil, fmt.Errorf("%s:%d: invalid decision: %q", path, i, d.decision)
+		}
+		d.path, d.row, d.col, err = parsePosition(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, i, err)
+		}
+		decisions = append(decisions, d)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read decisions file: %v", err)
+	}
+	return decisions, nil
+}
+
+// parsePosition returns the path, line and column of a file:line:col
+// position.
+func parsePosition(pos string) (path string, line, col int, err error) {
+	i := strings.LastIndex(pos, ":")
+	if i < 0 {
+		return "", 0, 0, fmt.Errorf("invalid position: %q", pos)
+	}
+	j := strings.LastIndex(pos[:i], ":")
+	if j < 0 {
+		return "", 0, 0, fmt.Errorf("invalid position: %q", pos)
+	}
+	line, err = strconv.Atoi(pos[j+1 : i])
+	if err != nil || line < 1 {
+		return "", 0, 0, fmt.Errorf("invalid line in position: %q", pos)
+	}
+	col, err = strconv.Atoi(pos[i+1:])
+	if err != nil || col < 1 {
+		return "", 0, 0, fmt.Errorf("invalid column in position: %q", pos)
+	}
+	return pos[:j], line, col, nil
+}
+
+// applyDecisions applies the fix and add decisions in the decisions file
+// at path. Failures to apply individual decisions are reported to stderr
+// and do not prevent other decisions from being applied.
+func applyDecisions(path string) int {
+	decisions, err := readDecisions(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return invocationError
+	}
+
+	status := success
+	for _, d := range decisions {
+		if d.decision == decideFix && d.replacement == "" {
+			fmt.Fprintf(os.Stderr, "%s:%d: no replacement for %q\n", path, d.line, d.word)
+			status |= invocationError
+		}
+	}
+	return status | applyDecided(decisions)
+}
+
+// applyDecided makes the fixes and .words additions held by the fix and add
+// decisions in decisions. Fix decisions without a replacement are ignored.
+// Failures to apply individual decisions are reported to stderr and do not
+// prevent other decisions from being applied.
+func applyDecided(decisions []decision) int {
+	status := success
+	fixes := make(map[string][]decision)
+	adds := make(map[string][]string)
+	for _, d := range decisions {
+		switch d.decision {
+		case decideFix:
+			if d.replacement == "" {
+				continue
+			}
+			fixes[d.path] = append(fixes[d.path], d)
+		case decideAdd:
+			root, err := moduleRoot(filepath.Dir(d.path))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s:%d:%d: %v\n", d.path, d.row, d.col, err)
+				status |= internalError
+				continue
+			}
+			adds[root] = append(adds[root], d.word)
+		}
+	}
+
+	for file, fix := range fixes {
+		_, errs := fixFile(file, fix)
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+			status |= internalError
+		}
+	}
+	for root, words := range adds {
+		err := addWords(filepath.Join(root, ".words"), words)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status |= internalError
+		}
+	}
+	return status
+}
+
+// fixFile replaces the words in the file at path with their replacements
+// as specified by fixes, returning the lines that were changed in order
+// and any errors. Fixes whose position does not hold the word are not
+// applied.
+func fixFile(path string, fixes []decision) (lines []int, errs []error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, []error{fmt.Errorf("failed to read %s: %v", path, err)}
+	}
+
+	// Line starts are needed to convert the line and column to
+	// a byte offset. Columns may extend past the end of their line
+	// for findings in multi-line literals and comments since they
+	// are reported relative to the start of the node.
+	starts := []int{0}
+	for i, c := range b {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+
+	type edit struct {
+		off int
+		decision
+	}
+	var edits []edit
+	for _, f := range fixes {
+		if f.row > len(starts) {
+			errs = append(errs, fmt.Errorf("%s:%d:%d: position out of range", path, f.row, f.col))
+			continue
+		}
+		off := starts[f.row-1] + f.col - 1
+		if !bytes.HasPrefix(b[min(off, len(b)):], []byte(f.word)) {
+			errs = append(errs, fmt.Errorf("%s:%d:%d: %q not found", path, f.row, f.col, f.word))
+			continue
+		}
+		edits = append(edits, edit{off: off, decision: f})
+	}
+	if len(edits) == 0 {
+		return nil, errs
+	}
+
+	// Apply from the end so offsets remain valid.
+	sort.Slice(edits, func(i, j int) bool { return edits[i].off > edits[j].off })
+	end := len(b) + 1
+	for _, e := range edits {
+		if e.off+len(e.word) > end {
+			errs = append(errs, fmt.Errorf("%s:%d:%d: overlapping fix for %q", path, e.row, e.col, e.word))
+			continue
+		}
+		b = append(b[:e.off], append([]byte(e.replacement), b[e.off+len(e.word):]...)...)
+		end = e.off
+		line := sort.SearchInts(starts, e.off+1)
+		if len(lines) == 0 || lines[0] != line {
+			lines = append([]int{line}, lines...)
+		}
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, append(errs, fmt.Errorf("failed to stat %s: %v", path, err))
+	}
+	err = os.WriteFile(path, b, fi.Mode().Perm())
+	if err != nil {
+		return nil, append(errs, fmt.Errorf("failed to write %s: %v", path, err))
+	}
+	return lines, errs
+}
+
+// moduleRoot returns the root directory of the module containing dir.
+func moduleRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		_, err := os.Stat(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			return dir, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no module root for %s", dir)
+		}
+		dir = parent
+	}
+}
+
+// addWords adds words to the .words file at path, creating it if it does
+// not exist. Words already in the file are not added again and the count
+// hint is updated.
+func addWords(path string, words []string) error {
+	var dict []string
+	b, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		dict = strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")[1:]
+	case errors.Is(err, fs.ErrNotExist):
+	default:
+		return fmt.Errorf("failed to open .words file: %v", err)
+	}
+
+	have := make(map[string]bool)
+	for _, w := range dict {
+		w, _, _ = strings.Cut(w, "/")
+		have[w] = true
+	}
+	for _, w := range words {
+		if have[w] {
+			continue
+		}
+		have[w] = true
+		dict = append(dict, w)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, len(dict))
+	for _, w := range dict {
+		fmt.Fprintln(&buf, w)
+	}
+	err = os.WriteFile(path, buf.Bytes(), 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write .words file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dictCacheVersion is the version of the base dictionary cache format.
+// It must be incremented when the format or the construction of the base
+// dictionary changes.
+const dictCacheVersion = 1
+
+// dictCacheMaxAge is the time since last use after which cached base
+// dictionaries are removed.
+const dictCacheMaxAge = 30 * 24 * time.Hour
+
+// baseDictKey returns a key identifying the base dictionary constructed
+// from the dictionary dic with the affix file aff for lang, gospel's known
+// words and the words of the dictionaries in paths with an explicit
+// language other than the one at index base. The key depends on the paths,
+// sizes and modification times of the dictionary files, not their contents.
+func baseDictKey(lang, aff, dic string, paths []dictPath, base int) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "version:%d\nlang:%s\n", dictCacheVersion, lang)
+	for _, f := range []string{aff, dic} {
+		err := hashFileInfo(h, f)
+		if err != nil {
+			return "", err
+		}
+	}
+	for i, p := range paths {
+		if p.lang == "" || i == base {
+			continue
+		}
+		_, dic, err := dictFiles(p.dir, p.lang)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "merge:%s rules:%t\n", p.lang, p.lang == lang)
+		err = hashFileInfo(h, dic)
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, w := range knownWords {
+		fmt.Fprintln(h, w)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFileInfo writes the path, size and modification time of the file
+// at path to w.
+func hashFileInfo(w io.Writer, path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "file:%s size:%d mod:%d\n", path, fi.Size(), fi.ModTime().UnixNano())
+	return err
+}
+
+// dictCachePath returns the path of the cached base dictionary for key.
+func dictCachePath(key string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gospel", key+".dic"), nil
+}
+
+// cachedLibrarian returns a librarian populated from the cached base
+// dictionary for key and whether the cached dictionary was found.
+func cachedLibrarian(aff, key string) (librarian, bool) {
+	path, err := dictCachePath(key)
+	if err != nil {
+		return librarian{}, false
+	}
+	l, err := newLibrarian(aff, path)
+	if err != nil {
+		return librarian{}, false
+	}
+	// Mark the entry as used so that it is not pruned.
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return l, true
+}
+
+// cacheLibrarian writes the words, affix rules and URLs held by the
+// librarian to the base dictionary cache for key.
+func cacheLibrarian(l librarian, key string) error {
+	path, err := dictCachePath(key)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file and rename so that concurrent
+	// runs never see a partially written dictionary.
+	f, err := os.CreateTemp(dir, "gospel")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+	w := bufio.NewWriter(f)
+	err = l.writeTo(w)
+	if err != nil {
+		return err
+	}
+	// URLs are held separately by the librarian, so write them
+	// after the words. They are recognised as URLs when the cache
+	// is read back.
+	urls := make([]string, 0, len(l.urls))
+	for u := range l.urls {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	for _, u := range urls {
+		_, err = fmt.Fprintln(w, u)
+		if err != nil {
+			return err
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Rename(f.Name(), path)
+	if err != nil {
+		return err
+	}
+	return pruneDictCache(dir, time.Now().Add(-dictCacheMaxAge))
+}
+
+// pruneDictCache removes cached base dictionaries and abandoned temporary
+// files in dir that were last used before the provided time.
+func pruneDictCache(dir string, before time.Time) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || (!strings.HasSuffix(name, ".dic") && !strings.HasPrefix(name, "gospel")) {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil || !fi.ModTime().Before(before) {
+			continue
+		}
+		err = os.Remove(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPruneDictCache(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	files := []struct {
+		name string
+		age  time.Duration
+	}{
+		{name: "old.dic", age: 2 * dictCacheMaxAge},
+		{name: "new.dic", age: time.Hour},
+		{name: "gospel123", age: 2 * dictCacheMaxAge},
+		{name: "other.txt", age: 2 * dictCacheMaxAge},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		err := os.WriteFile(path, []byte("1\nword\n"), 0o644)
+		if err != nil {
+			t.Fatalf("unexpected error writing %s: %v", f.name, err)
+		}
+		mod := now.Add(-f.age)
+		err = os.Chtimes(path, mod, mod)
+		if err != nil {
+			t.Fatalf("unexpected error setting times for %s: %v", f.name, err)
+		}
+	}
+
+	err := pruneDictCache(dir, now.Add(-dictCacheMaxAge))
+	if err != nil {
+		t.Fatalf("unexpected error pruning cache: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error reading cache: %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	sort.Strings(got)
+	want := []string{"new.dic", "other.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected files after pruning: got:%q want:%q", got, want)
+	}
+}
@@ -15,24 +15,22 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
-	"github.com/kortschak/hunspell"
 	"golang.org/x/tools/go/packages"
 )
 
 // dictionary is a spelling dictionary that can record misspelled words.
 type dictionary struct {
-	*hunspell.Spell
+	speller
 
 	config
 
-	// misspellings is the number of misspellings found.
-	misspellings int
-
 	// misspelled is the complete list of misspelled words
 	// found during the check. The words must have had any
 	// leading and trailing underscores removed.
@@ -41,15 +39,80 @@ type dictionary struct {
 	// roots is the set of module roots.
 	roots map[string]bool
 
+	// harvested is the number of words added
+	// from each harvest source.
+	harvested harvestCounts
+
 	// ignoredURLs is the set of URLs to omit from checking
 	// target validity.
 	ignoredURLs map[string]bool
+
+	// conditioner is used to normalise words before they
+	// are checked. It is nil if no conditioning is done.
+	conditioner *strings.Replacer
+
+	// others holds the dictionaries of languages
+	// other than the base language. Words are correct
+	// if any dictionary accepts them.
+	others []speller
+
+	// identifiers is the set of words harvested from
+	// identifiers and imported symbols that were not
+	// already accepted by the dictionary. Possessive
+	// and plural forms of these words are accepted.
+	identifiers map[string]bool
+
+	// suggesters holds the spellers used to find
+	// suggestions when searches are time limited,
+	// so that an abandoned search does not hold the
+	// spellers used for checking. The first is for
+	// the base language and the rest are for the
+	// other languages. It is nil until suggestions
+	// are first sought and after a search has been
+	// abandoned, when a new set is opened with
+	// openSuggesters.
+	suggesters     []speller
+	openSuggesters func() ([]speller, error)
+
+	// temp holds the paths of temporary dictionary
+	// files that are needed to open suggesters. They
+	// are removed when the dictionary is closed.
+	temp []string
 }
 
 // newDictionary returns a new dictionary based on the provided packages
 // and configuration.
 func newDictionary(pkgs []*packages.Package, cfg config) (*dictionary, error) {
-	d := dictionary{config: cfg}
+	return newRootsDictionary(pkgs, moduleRoots(pkgs), cfg)
+}
+
+// moduleRoots returns the set of module roots of the provided packages.
+func moduleRoots(pkgs []*packages.Package) map[string]bool {
+	roots := make(map[string]bool)
+	for _, p := range pkgs {
+		if p.Module == nil {
+			continue
+		}
+		roots[p.Module.Dir] = true
+	}
+	return roots
+}
+
+// newRootsDictionary returns a new dictionary based on the provided
+// packages and configuration, merging the dictionaries and affix rules
+// held in the provided module roots.
+func newRootsDictionary(pkgs []*packages.Package, roots map[string]bool, cfg config) (*dictionary, error) {
+	d := dictionary{config: cfg, harvested: make(harvestCounts)}
+	// Temporary dictionary files are kept for opening suggesters
+	// if the dictionary is constructed and searches are limited.
+	var (
+		temp []string
+		keep bool
+	)
+	// The first language in the list is the base language that the
+	// project's words and affix rules are added to.
+	lang, others, _ := strings.Cut(cfg.Lang, ",")
+	cfg.Lang = strings.TrimSpace(lang)
 	if d.words != "" {
 		d.misspelled = make(map[string]bool)
 	}
@@ -60,33 +123,106 @@ func newDictionary(pkgs []*packages.Package, cfg config) (*dictionary, error) {
 	var (
 		ook      librarian
 		aff, dic string
+		key      string
+		cached   bool
 		err      error
 	)
-	for _, p := range filepath.SplitList(d.paths) {
-		if strings.HasPrefix(p, "~"+string(filepath.Separator)) {
-			dir, err := os.UserHomeDir()
-			if err != nil {
-				return nil, fmt.Errorf("could not expand tilde: %v", err)
-			}
-			p = filepath.Join(dir, p[2:])
-		}
-		aff, dic, err = hunspell.Paths(p, cfg.Lang)
-		if err != nil {
-			return nil, fmt.Errorf("could not find dictionary: %v", err)
+	pathList := dictPathList(d.paths)
+	paths, err := parseDictPaths(pathList)
+	if err != nil {
+		return nil, err
+	}
+	var base int
+	if cfg.Engine == pipeEngine {
+		// The spelling program provides the language's
+		// dictionary, so only gospel's known words and
+		// the project's words are added to its session.
+		ook = librarian{
+			rules: make(map[string]string),
+			urls:  make(map[string]bool),
 		}
-		ook, err = newLibrarian(aff, dic)
-		if err == nil {
-			for _, w := range knownWords {
-				err = ook.addWord(w)
+	} else {
+		// Find the base dictionary, preferring the first directory
+		// holding the configured language that was not given an
+		// explicit language and then the first with the configured
+		// language given explicitly.
+		for _, explicit := range []bool{false, true} {
+			for i, p := range paths {
+				if (p.lang != "") != explicit || (explicit && p.lang != cfg.Lang) {
+					continue
+				}
+				aff, dic, err = dictFiles(p.dir, cfg.Lang)
 				if err != nil {
-					return nil, fmt.Errorf("%w in internal dictionary", err)
+					return nil, fmt.Errorf("could not find dictionary: %v", err)
+				}
+				if cfg.CacheDict {
+					// Failing to construct a key only means
+					// that the cache is not used, so errors
+					// are handled when the base dictionary
+					// is built below.
+					var kerr error
+					key, kerr = baseDictKey(cfg.Lang, aff, dic, paths, i)
+					if kerr == nil {
+						ook, cached = cachedLibrarian(aff, key)
+						if cached {
+							base = i
+							break
+						}
+					} else {
+						key = ""
+					}
+				}
+				ook, err = newLibrarian(aff, dic)
+				if err == nil {
+					base = i
+					break
 				}
 			}
-			break
+			if ook.rules != nil {
+				break
+			}
 		}
 	}
 	if ook.rules == nil {
-		return nil, fmt.Errorf("no %s dictionary found in: %v", d.Lang, d.paths)
+		return nil, fmt.Errorf("no %s dictionary found in: %v", cfg.Lang, pathList)
+	}
+	if !cached {
+		for _, w := range knownWords {
+			err = ook.addWord(w)
+			if err != nil {
+				return nil, fmt.Errorf("%w in internal dictionary", err)
+			}
+		}
+		// Merge words from all other dictionaries that have been
+		// explicitly requested. Affix rules are only retained when
+		// the dictionary is for the same language as the base since
+		// rules are defined by each language's affix file.
+		for i, p := range paths {
+			if p.lang == "" || i == base || cfg.Engine == pipeEngine {
+				continue
+			}
+			_, dic, err := dictFiles(p.dir, p.lang)
+			if err != nil {
+				return nil, fmt.Errorf("could not find dictionary: %v", err)
+			}
+			err = ook.addDictionary(dic, p.lang == cfg.Lang)
+			if err != nil {
+				return nil, fmt.Errorf("could not read %s dictionary: %w", p.lang, err)
+			}
+		}
+		if key != "" {
+			// The cache is an optimisation, so failing
+			// to write it is not an error.
+			cacheLibrarian(ook, key)
+		}
+	}
+	if cfg.ConditionInput {
+		// The hunspell engine and the programs run by the pipe
+		// engine apply ICONV conversions themselves.
+		d.conditioner, err = newConditioner(aff, cfg.Engine == builtinEngine)
+		if err != nil {
+			return nil, fmt.Errorf("could not read input conversions: %v", err)
+		}
 	}
 
 	// Load any dictionaries that exist in well known locations
@@ -95,15 +231,17 @@ func newDictionary(pkgs []*packages.Package, cfg config) (*dictionary, error) {
 	// it is appended to the existing list, unless we are making
 	// and updated dictionary when we will merge them.
 	if d.words == "" || d.update {
-		d.roots = make(map[string]bool)
-		for _, p := range pkgs {
-			if p.Module == nil {
-				continue
+		d.roots = roots
+		for r := range d.roots {
+			err := ook.addDictionary(filepath.Join(r, ".words"), true)
+			if _, ok := err.(*os.PathError); !ok && err != nil {
+				return nil, err
 			}
-			d.roots[p.Module.Dir] = true
 		}
-		for r := range d.roots {
-			err := ook.addDictionary(filepath.Join(r, ".words"))
+		// Also merge the user's hunspell or ispell
+		// personal word list if one is set.
+		if wl := os.Getenv("WORDLIST"); wl != "" {
+			err := ook.addWordList(wl)
 			if _, ok := err.(*os.PathError); !ok && err != nil {
 				return nil, err
 			}
@@ -117,11 +255,14 @@ func newDictionary(pkgs []*packages.Package, cfg config) (*dictionary, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create known words dictionary: %v", err)
 	}
+	temp = append(temp, kw.Name())
 	defer func() {
 		// In case we fail the write, close the file to allow
 		// intransigent operating systems to delete it.
 		kw.Close()
-		os.Remove(kw.Name())
+		if !keep {
+			os.Remove(kw.Name())
+		}
 	}()
 	err = ook.writeTo(kw)
 	if err != nil {
@@ -132,10 +273,86 @@ func newDictionary(pkgs []*packages.Package, cfg config) (*dictionary, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to write known words dictionary: %v", err)
 	}
-	d.Spell, err = hunspell.NewSpellPaths(aff, dic)
+
+	// Merge any project affix rules with the language's affix
+	// rules. As with the known words, this requires a write to
+	// disk.
+	var extra []string
+	for r := range d.roots {
+		path := filepath.Join(r, affixFile)
+		if _, err := os.Stat(path); err == nil {
+			extra = append(extra, path)
+		}
+	}
+	if len(extra) != 0 && cfg.Engine != pipeEngine {
+		// The pipe protocol has no way to add affix
+		// rules, so they are not merged for the pipe
+		// engine.
+		sort.Strings(extra)
+		af, err := os.CreateTemp("", "gospel")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create affix file: %v", err)
+		}
+		temp = append(temp, af.Name())
+		defer func() {
+			af.Close()
+			if !keep {
+				os.Remove(af.Name())
+			}
+		}()
+		err = mergeAffixes(af, aff, extra)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge affix rules: %v", err)
+		}
+		aff = af.Name()
+		err = af.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to write affix file: %v", err)
+		}
+	}
+
+	d.speller, err = newSpeller(cfg, cfg.Lang, aff, dic)
 	if err != nil {
 		return nil, fmt.Errorf("could not open dictionary: %v", err)
 	}
+	var langs []string
+	if others != "" {
+		for _, l := range strings.Split(others, ",") {
+			l = strings.TrimSpace(l)
+			s, err := newLanguageSpeller(cfg, paths, l)
+			if err != nil {
+				return nil, fmt.Errorf("%w in: %v", err, pathList)
+			}
+			d.others = append(d.others, s)
+			langs = append(langs, l)
+		}
+	}
+
+	// Words added to the base dictionary below are recorded
+	// when suggestion searches are time limited so that they
+	// can be added to the suggesters.
+	spelling := d.speller
+	if cfg.SuggestTimeout > 0 {
+		added := &addedWords{speller: d.speller}
+		spelling = added
+		d.openSuggesters = func() ([]speller, error) {
+			s, err := newSpeller(cfg, cfg.Lang, aff, dic)
+			if err != nil {
+				return nil, err
+			}
+			added.addTo(s)
+			suggesters := []speller{s}
+			for _, l := range langs {
+				s, err := newLanguageSpeller(cfg, paths, l)
+				if err != nil {
+					closeSpellers(suggesters)
+					return nil, err
+				}
+				suggesters = append(suggesters, s)
+			}
+			return suggesters, nil
+		}
+	}
 
 	// Get URLs if we are ignoring them.
 	if d.CheckURLs {
@@ -146,39 +363,298 @@ func newDictionary(pkgs []*packages.Package, cfg config) (*dictionary, error) {
 	if cfg.ReadLicenses {
 		const licenseThreshold = 75 // Threshold for matching a license.
 		for r := range d.roots {
-			readLicenses(d.Spell, r, licenseThreshold)
+			readLicenses(spelling, r, licenseThreshold, d.harvested)
 		}
 	}
 	if cfg.GitLog {
-		readGitLog(d.Spell)
+		readGitLog(spelling, d.harvested)
 	}
 
+	// Identifiers and other harvested words are added after the
+	// project's .words have been merged into the dictionary, and
+	// only when they are not already correct, so affix rules given
+	// in .words are not masked by bare additions of the same words.
 	if cfg.IgnoreIdents {
-		err = addIdentifiers(d.Spell, pkgs, make(map[string]bool))
+		d.identifiers = make(map[string]bool)
+		err = addIdentifiers(spelling, pkgs, make(map[string]bool), cfg.Harvest, d.harvested, d.identifiers)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	// Add authors identifiers gleaned from NOTEs.
-	for _, p := range pkgs {
-		for _, f := range p.Syntax {
-			addNoteAuthors(d.Spell, f.Comments)
+	if cfg.Harvest.NoteAuthors {
+		for _, p := range pkgs {
+			for _, f := range p.Syntax {
+				addNoteAuthors(spelling, f.Comments, d.harvested)
+			}
 		}
 	}
 
+	if d.openSuggesters != nil {
+		keep = true
+		d.temp = temp
+	}
+
 	return &d, nil
 }
 
+// newLanguageSpeller returns a speller using the configured engine for the
+// first dictionary for lang in paths, preferring directories that were not
+// given an explicit language.
+func newLanguageSpeller(cfg config, paths []dictPath, lang string) (speller, error) {
+	if cfg.Engine == pipeEngine {
+		// The spelling program provides the dictionary.
+		return newSpeller(cfg, lang, "", "")
+	}
+	for _, explicit := range []bool{false, true} {
+		for _, p := range paths {
+			if (p.lang != "") != explicit || (explicit && p.lang != lang) {
+				continue
+			}
+			aff, dic, err := dictFiles(p.dir, lang)
+			if err != nil {
+				return nil, fmt.Errorf("could not find dictionary: %v", err)
+			}
+			s, err := newSpeller(cfg, lang, aff, dic)
+			if err == nil {
+				return s, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no %s dictionary found", lang)
+}
+
+// Close releases the resources held by the dictionary's spellers and
+// removes its temporary files. It returns the first error reported by any
+// of the spellers. Suggesters running an abandoned search are not closed.
+func (d *dictionary) Close() error {
+	err := closeSpellers(append([]speller{d.speller}, d.others...))
+	if d.suggesters != nil {
+		serr := closeSpellers(d.suggesters)
+		if err == nil {
+			err = serr
+		}
+	}
+	for _, path := range d.temp {
+		os.Remove(path)
+	}
+	return err
+}
+
+// closeSpellers closes the spellers that hold resources, returning the
+// first error reported by any of them.
+func closeSpellers(spellers []speller) error {
+	var err error
+	for _, s := range spellers {
+		c, ok := s.(io.Closer)
+		if !ok {
+			continue
+		}
+		cerr := c.Close()
+		if err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// IsCorrect returns whether word is correct in the base dictionary or in
+// the dictionary of any other configured language.
+func (d *dictionary) IsCorrect(word string) bool {
+	if d.speller.IsCorrect(word) {
+		return true
+	}
+	for _, s := range d.others {
+		if s.IsCorrect(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Suggest returns the suggestions for word from the base dictionary,
+// followed by the suggestions from the dictionaries of other configured
+// languages that have not already been suggested.
+func (d *dictionary) Suggest(word string) []string {
+	return suggestAll(append([]speller{d.speller}, d.others...), word)
+}
+
+// suggestWithin returns the suggestions for word as Suggest does, but
+// abandons the search if it takes longer than timeout, returning false.
+// The search is made with the dictionary's suggesters rather than the
+// spellers used for checking. Suggesters running an abandoned search are
+// left to complete it and are replaced, so that neither checking nor
+// later searches wait for it. If suggesters cannot be opened, the search
+// is made with the spellers used for checking and is not limited.
+func (d *dictionary) suggestWithin(word string, timeout time.Duration) (suggestions []string, ok bool) {
+	if d.suggesters == nil {
+		var err error
+		d.suggesters, err = d.openSuggesters()
+		if err != nil {
+			return d.Suggest(word), true
+		}
+	}
+	suggesters := d.suggesters
+	done := make(chan []string, 1)
+	go func() { done <- suggestAll(suggesters, word) }()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case suggestions = <-done:
+		return suggestions, true
+	case <-timer.C:
+		d.suggesters = nil
+		go func() {
+			<-done
+			closeSpellers(suggesters)
+		}()
+		return nil, false
+	}
+}
+
+// suggestAll returns the suggestions for word from the first speller,
+// followed by the suggestions from the remaining spellers that have not
+// already been suggested.
+func suggestAll(spellers []speller, word string) []string {
+	suggestions := spellers[0].Suggest(word)
+	if len(spellers) == 1 {
+		return suggestions
+	}
+	seen := make(map[string]bool)
+	for _, s := range suggestions {
+		seen[s] = true
+	}
+	for _, o := range spellers[1:] {
+		for _, s := range o.Suggest(word) {
+			if !seen[s] {
+				seen[s] = true
+				suggestions = append(suggestions, s)
+			}
+		}
+	}
+	return suggestions
+}
+
+// addedWords is a speller that records the words added to it so that they
+// can be added to other spellers.
+type addedWords struct {
+	speller
+	words []addedWord
+}
+
+// addedWord is a word added to a speller, with the example word for its
+// affix rules if it was added with affixes.
+type addedWord struct {
+	word, example string
+}
+
+// Add implements speller, recording the word if it is added.
+func (s *addedWords) Add(word string) bool {
+	ok := s.speller.Add(word)
+	if ok {
+		s.words = append(s.words, addedWord{word: word})
+	}
+	return ok
+}
+
+// AddWithAffix implements speller, recording the word if it is added.
+func (s *addedWords) AddWithAffix(word, example string) bool {
+	ok := s.speller.AddWithAffix(word, example)
+	if ok {
+		s.words = append(s.words, addedWord{word: word, example: example})
+	}
+	return ok
+}
+
+// addTo adds the recorded words to dst.
+func (s *addedWords) addTo(dst speller) {
+	for _, w := range s.words {
+		if w.example == "" {
+			dst.Add(w.word)
+		} else {
+			dst.AddWithAffix(w.word, w.example)
+		}
+	}
+}
+
+// condition returns the word normalised by the dictionary's conditioner.
+func (d *dictionary) condition(word string) string {
+	if d.conditioner == nil {
+		return word
+	}
+	return d.conditioner.Replace(word)
+}
+
+// dictPath is a hunspell dictionary directory.
+type dictPath struct {
+	dir string
+
+	// lang is the explicitly specified
+	// language for the directory. It is
+	// empty if no language was given.
+	lang string
+}
+
+// dictPathList returns the dictionary path list with the directories in
+// the DICPATH environment variable used by hunspell appended, so that
+// they are searched when no directory in list holds the language.
+func dictPathList(list string) string {
+	env := os.Getenv("DICPATH")
+	switch {
+	case env == "":
+		return list
+	case list == "":
+		return env
+	}
+	return list + string(filepath.ListSeparator) + env
+}
+
+// langTag matches hunspell dictionary language keys.
+var langTag = regexp.MustCompile(`^[a-z]{2,3}(?:[_-][A-Za-z0-9]+)*$`)
+
+// parseDictPaths returns the dictionary directories in the provided path
+// list. A directory in the list may be followed by a language key to
+// explicitly request that dictionary, for example "/usr/share/hunspell:en_US".
+// List elements that look like a language key and are not existing
+// directories are treated as a language for the preceding directory.
+func parseDictPaths(list string) ([]dictPath, error) {
+	var paths []dictPath
+	for _, p := range filepath.SplitList(list) {
+		if len(paths) != 0 && paths[len(paths)-1].lang == "" && langTag.MatchString(p) {
+			if fi, err := os.Stat(p); err != nil || !fi.IsDir() {
+				paths[len(paths)-1].lang = p
+				continue
+			}
+		}
+		if strings.HasPrefix(p, "~"+string(filepath.Separator)) {
+			dir, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("could not expand tilde: %v", err)
+			}
+			p = filepath.Join(dir, p[2:])
+		}
+		paths = append(paths, dictPath{dir: p})
+	}
+	return paths, nil
+}
+
 // noteMisspelling records the word as a misspelling if a words file was
 // requested.
 func (d *dictionary) noteMisspelling(word string) {
-	d.misspellings++
 	if d.misspelled != nil {
 		d.misspelled[word] = true
 	}
 }
 
+// noteWord records the dictionary entry in the words file if one was
+// requested, without counting it as a misspelling.
+func (d *dictionary) noteWord(entry string) {
+	if d.misspelled != nil {
+		d.misspelled[entry] = true
+	}
+}
+
 // writeMisspellings writes the recorded misspellings to the words file.
 func (d *dictionary) writeMisspellings() error {
 	// Write out a dictionary of the misspelled words.
@@ -204,14 +680,32 @@ func (d *dictionary) writeMisspellings() error {
 			}
 		}
 
+		// Merge the affix rules of duplicated words.
+		l := librarian{
+			rules: make(map[string]string),
+			urls:  make(map[string]bool),
+		}
+		for m := range d.misspelled {
+			err := l.addWord(m)
+			if err != nil {
+				return fmt.Errorf("failed to collate new dictionary: %v", err)
+			}
+		}
+
 		f, err := os.Create(d.words)
 		if err != nil {
 			return fmt.Errorf("failed to open misspellings file: %v", err)
 		}
 		defer f.Close()
-		dict := make([]string, 0, len(d.misspelled))
-		for m := range d.misspelled {
-			dict = append(dict, m)
+		dict := make([]string, 0, len(l.rules)+len(l.urls))
+		for w, r := range l.rules {
+			if r != "" {
+				w += "/" + r
+			}
+			dict = append(dict, w)
+		}
+		for u := range l.urls {
+			dict = append(dict, u)
 		}
 		sort.Strings(dict)
 		_, err = fmt.Fprintln(f, len(dict))
@@ -229,30 +723,74 @@ func (d *dictionary) writeMisspellings() error {
 	return nil
 }
 
-// addIdentifiers adds identifier labels to the spelling dictionary.
-func addIdentifiers(spelling *hunspell.Spell, pkgs []*packages.Package, seen map[string]bool) error {
-	v := &adder{spelling: spelling}
+// isIdentifierForm returns whether word is a possessive or plural form of
+// a word harvested from identifiers or imported symbols. The plural "es"
+// suffix is only accepted after s, x, z, ch and sh.
+func (d *dictionary) isIdentifierForm(word string) bool {
+	if len(d.identifiers) == 0 {
+		return false
+	}
+	for _, suffix := range []string{"'s", "’s", "es", "s"} {
+		base, ok := strings.CutSuffix(word, suffix)
+		if !ok || len(base) < 2 || !d.identifiers[base] {
+			continue
+		}
+		if suffix == "es" && !hasSibilantSuffix(base) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// hasSibilantSuffix returns whether word ends with a sibilant that
+// takes an "es" plural.
+func hasSibilantSuffix(word string) bool {
+	for _, suffix := range []string{"s", "x", "z", "ch", "sh"} {
+		if strings.HasSuffix(strings.ToLower(word), suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// addIdentifiers adds identifier labels from the harvest sources to the
+// spelling dictionary, counting the words added in counts and recording
+// words from identifiers and imported symbols in idents.
+func addIdentifiers(spelling speller, pkgs []*packages.Package, seen map[string]bool, sources harvestSet, counts harvestCounts, idents map[string]bool) error {
+	v := &adder{spelling: spelling, sources: sources, counts: counts, idents: idents}
 	for _, p := range pkgs {
 		v.pkg = p
-		for _, e := range strings.Split(p.String(), "/") {
-			if !spelling.IsCorrect(e) {
-				spelling.Add(e)
+		if sources.PackagePaths {
+			for _, e := range strings.Split(p.String(), "/") {
+				counts.add(spelling, pathSource, e)
+			}
+		}
+		if sources.Directives {
+			for _, w := range directiveWords(p.Syntax, p.Fset) {
+				counts.add(spelling, directiveSource, w)
 			}
 		}
-		for _, w := range directiveWords(p.Syntax, p.Fset) {
-			if !spelling.IsCorrect(w) {
-				spelling.Add(w)
+		if sources.ImportedSymbols {
+			// Add these before walking the syntax so
+			// that type names used in the package are
+			// not first added without plural forms.
+			for _, dep := range sortedImports(p) {
+				v.addExported(dep)
 			}
 		}
 		for _, f := range p.Syntax {
 			ast.Walk(v, f)
 		}
-		for _, dep := range p.Imports {
+		// Only the symbols of direct imports are added.
+		depSources := sources
+		depSources.ImportedSymbols = false
+		for _, dep := range sortedImports(p) {
 			if seen[dep.String()] {
 				continue
 			}
 			seen[dep.String()] = true
-			addIdentifiers(spelling, []*packages.Package{dep}, seen)
+			addIdentifiers(spelling, []*packages.Package{dep}, seen, depSources, counts, idents)
 		}
 	}
 	if v.failed != 0 {
@@ -261,6 +799,22 @@ func addIdentifiers(spelling *hunspell.Spell, pkgs []*packages.Package, seen map
 	return nil
 }
 
+// sortedImports returns the packages imported by p in import path order.
+// Words harvested from one package can change how words from another are
+// added, so imports are visited in a stable order.
+func sortedImports(p *packages.Package) []*packages.Package {
+	paths := make([]string, 0, len(p.Imports))
+	for path := range p.Imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	deps := make([]*packages.Package, len(paths))
+	for i, path := range paths {
+		deps[i] = p.Imports[path]
+	}
+	return deps
+}
+
 // directiveWords returns words used in directive comments.
 func directiveWords(files []*ast.File, fset *token.FileSet) []string {
 	var words []string
@@ -297,7 +851,10 @@ func directiveWords(files []*ast.File, fset *token.FileSet) []string {
 
 // adder is an ast.Visitor that adds tokens to a spelling dictionary.
 type adder struct {
-	spelling *hunspell.Spell
+	spelling speller
+	sources  harvestSet
+	counts   harvestCounts
+	idents   map[string]bool
 	failed   int
 	pkg      *packages.Package
 }
@@ -306,11 +863,17 @@ type adder struct {
 func (a *adder) Visit(n ast.Node) ast.Visitor {
 	switch n := n.(type) {
 	case *ast.Ident:
+		if !a.sources.Identifiers {
+			break
+		}
 		// Check whether this is a type and only make it
 		// countable in that case.
 		ok := n.Obj != nil && n.Obj.Kind == ast.Typ
-		a.addWordUnknownWord(stripUnderscores(n.Name), ok)
+		a.addWordUnknownWord(stripUnderscores(n.Name), identSource, ok)
 	case *ast.StructType:
+		if !a.sources.TagKeys && !a.sources.TagValues {
+			break
+		}
 		typ, ok := a.pkg.TypesInfo.Types[n].Type.(*types.Struct)
 		if !ok {
 			break
@@ -320,15 +883,41 @@ func (a *adder) Visit(n ast.Node) ast.Visitor {
 			if !f.Exported() {
 				continue
 			}
-			for _, w := range extractStructTagWords(typ.Tag(i)) {
-				a.addWordUnknownWord(w, false)
+			keys, values := extractStructTagWords(typ.Tag(i))
+			if a.sources.TagKeys {
+				for _, w := range keys {
+					a.addWordUnknownWord(w, tagKeySource, false)
+				}
+			}
+			if a.sources.TagValues {
+				for _, w := range values {
+					a.addWordUnknownWord(w, tagValueSource, false)
+				}
 			}
 		}
 	}
 	return a
 }
 
-func (a *adder) addWordUnknownWord(w string, countable bool) {
+// addExported adds the names of the exported package-level symbols of
+// the imported package p to the dictionary. Type names are added with the
+// affix rules of a countable noun, other names are added without affixes.
+func (a *adder) addExported(p *packages.Package) {
+	if p.Types == nil {
+		return
+	}
+	scope := p.Types.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		_, isType := obj.(*types.TypeName)
+		a.addWordUnknownWord(stripUnderscores(name), importSource, isType)
+	}
+}
+
+func (a *adder) addWordUnknownWord(w, source string, countable bool) {
 	if a.spelling.IsCorrect(w) {
 		// Assume we have the correct plurality rules.
 		// This should work most of the time. If it turns
@@ -336,6 +925,13 @@ func (a *adder) addWordUnknownWord(w string, countable bool) {
 		// on countable and always add those terms.
 		return
 	}
+	if source == identSource || source == importSource {
+		// Only words that the dictionary did not already
+		// accept have their forms accepted by the identifier
+		// form heuristic, leaving other words to the affix
+		// rules of the dictionary.
+		a.idents[w] = true
+	}
 	var ok bool
 	if countable {
 		ok = a.spelling.AddWithAffix(w, "item")
@@ -344,7 +940,9 @@ func (a *adder) addWordUnknownWord(w string, countable bool) {
 	}
 	if !ok {
 		a.failed++
+		return
 	}
+	a.counts[source]++
 }
 
 // a librarian collates dictionaries.
@@ -365,7 +963,7 @@ func newLibrarian(aff, dic string) (librarian, error) {
 		rules: make(map[string]string),
 		urls:  make(map[string]bool),
 	}
-	err = l.addDictionary(dic)
+	err = l.addDictionary(dic, true)
 	if err != nil {
 		return librarian{}, err
 	}
@@ -373,8 +971,9 @@ func newLibrarian(aff, dic string) (librarian, error) {
 }
 
 // addDictionary adds word rules from the hunspell dictionary at the given
-// path.
-func (l librarian) addDictionary(path string) error {
+// path. If rules is false, only the words are added and their affix rules
+// are discarded.
+func (l librarian) addDictionary(path string, rules bool) error {
 	f, err := os.Open(path)
 	if err != nil {
 		return err
@@ -386,7 +985,11 @@ func (l librarian) addDictionary(path string) error {
 			// Skip word count line.
 			continue
 		}
-		err := l.addWord(sc.Text())
+		w := sc.Text()
+		if !rules && !urls.MatchString(w) {
+			w = strings.SplitN(w, "/", 2)[0]
+		}
+		err := l.addWord(w)
 		if err != nil {
 			return fmt.Errorf("%w at %s:%d", err, path, i+1)
 		}
@@ -394,6 +997,34 @@ func (l librarian) addDictionary(path string) error {
 	return sc.Err()
 }
 
+// addWordList adds the words in the hunspell or ispell personal word list
+// at path to the librarian's dictionary. Personal word lists have no word
+// count line, and words may be followed by a slash and an example word
+// for affixes, which is ignored. Forbidden words, marked with a leading
+// asterisk, are not added.
+func (l librarian) addWordList(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for i := 1; sc.Scan(); i++ {
+		w := strings.TrimSpace(sc.Text())
+		if w == "" || strings.HasPrefix(w, "*") {
+			continue
+		}
+		if !urls.MatchString(w) {
+			w = strings.SplitN(w, "/", 2)[0]
+		}
+		err := l.addWord(w)
+		if err != nil {
+			return fmt.Errorf("%w at %s:%d", err, path, i)
+		}
+	}
+	return sc.Err()
+}
+
 // addWord adds the provided word to the librarian's dictionary merging any
 // affix rules into those already existing for the word.
 func (l librarian) addWord(w string) error {
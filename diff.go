@@ -27,12 +27,21 @@ func (f changeFilter) isInChange(pos token.Pos, fset positioner) bool {
 		return true
 	}
 	p := fset.Position(pos)
-	lines, ok := f[rel(p.Filename)]
+	return f.hasLine(p.Filename, p.Line)
+}
+
+// hasLine returns whether the line of the file at path is in changes in
+// the filter. If f is nil all lines are included.
+func (f changeFilter) hasLine(path string, line int) bool {
+	if f == nil {
+		return true
+	}
+	lines, ok := f[wdRel(path)]
 	if !ok {
 		return false
 	}
 	for _, r := range lines {
-		if r.start <= p.Line && p.Line <= r.end {
+		if r.start <= line && line <= r.end {
 			return true
 		}
 	}
@@ -45,7 +54,7 @@ func (f changeFilter) fileIsInChange(pos token.Pos, fset positioner) bool {
 	if f == nil {
 		return true
 	}
-	_, ok := f[rel(fset.Position(pos).Filename)]
+	_, ok := f[wdRel(fset.Position(pos).Filename)]
 	return ok
 }
 
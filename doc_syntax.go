@@ -0,0 +1,74 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// docBlock is the state of an indented span of doc comment lines.
+type docBlock struct {
+	// indented is whether the previous
+	// non-blank line was indented.
+	indented bool
+
+	// list is whether the indented span
+	// is a list rather than a code block.
+	list bool
+}
+
+var (
+	// linkDef matches doc comment link definitions, for
+	// example "[RFC 8259]: https://...".
+	linkDef = regexp.MustCompile(`^\[[^\]]+\]:\s*\S+$`)
+
+	// docMarkup matches inline code spans and the
+	// targets of Markdown links in doc comments.
+	docMarkup = regexp.MustCompile("`[^`]+`|\\]\\([^)\\s]*\\)")
+)
+
+// maskDocSyntax returns the line comment text with the structure of Go doc
+// comments and common Markdown markup masked, and the state of the indented
+// span at the end of the text. Indented code blocks, link definitions,
+// inline code spans and the targets of Markdown links are replaced with
+// spaces, leaving prose, list items and link text to be checked. The block
+// state holds whether the text starts within an indented span begun by an
+// earlier comment. Line endings are retained so that positions within the
+// text are not altered.
+func maskDocSyntax(text string, block docBlock) (string, docBlock) {
+	if !strings.HasPrefix(text, "//") {
+		return docMarkup.ReplaceAllStringFunc(text, maskMarkup), block
+	}
+	content := text[len("//"):]
+	switch {
+	case strings.TrimSpace(content) == "":
+		// Blank lines may be within code blocks
+		// and lists, so the state is retained.
+		return text, block
+	case strings.HasPrefix(content, "\t") || strings.HasPrefix(content, "  "):
+		if !block.indented {
+			block = docBlock{indented: true, list: listMarker.MatchString(strings.TrimLeft(content, " \t"))}
+		}
+		if !block.list {
+			return blank(text), block
+		}
+	default:
+		block = docBlock{}
+		if linkDef.MatchString(strings.TrimSpace(content)) {
+			return blank(text), block
+		}
+	}
+	return docMarkup.ReplaceAllStringFunc(text, maskMarkup), block
+}
+
+// maskMarkup returns the docMarkup match s with code spans replaced with
+// spaces and the leading bracket of link targets retained.
+func maskMarkup(s string) string {
+	if strings.HasPrefix(s, "]") {
+		return "]" + blank(s[1:])
+	}
+	return blank(s)
+}
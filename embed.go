@@ -5,7 +5,11 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
 	"go/token"
+	"io"
 	"os"
 	"sort"
 	"unicode"
@@ -17,9 +21,16 @@ type embedded struct {
 	path  string
 	data  string
 	lines []int
+
+	// skipped is the reason the data was
+	// not checked or was only partially
+	// checked. It is empty if the data
+	// was loaded in full.
+	skipped string
 }
 
 // loadEmbedded reads the file at the provided path as an embedded.
+// Gzip compressed files are decompressed before being considered.
 // If the data in the file is not valid UTF-8, contains bytes not found
 // in ASCII or UTF-8 text, or contains lines longer than maxLineLen, no
 // line-based position information will be retained and the file will be
@@ -29,9 +40,15 @@ func (c *checker) loadEmbedded(path string, maxLineLen int) (*embedded, error) {
 	if err != nil {
 		return nil, err
 	}
-	e := &embedded{path: path, data: string(b)}
+	e := &embedded{path: path}
+	b, err = decompress(b)
+	if err != nil {
+		e.skipped = fmt.Sprintf("embedded file checked without decompression (%v)", err)
+	}
+	e.data = string(b)
 	if c.unexpectedEntropy(e.data, false) { // Consider all characters for entropy.
 		e.data = ""
+		e.skipped = "embedded file not checked (unexpected entropy)"
 		return e, nil
 	}
 	if !utf8.ValidString(e.data) {
@@ -50,6 +67,31 @@ func (c *checker) loadEmbedded(path string, maxLineLen int) (*embedded, error) {
 	return e, nil
 }
 
+// maxDecompressed is the maximum size of decompressed embedded data.
+const maxDecompressed = 16 << 20
+
+// decompress returns the decompressed contents of b if it is gzip
+// compressed, and b otherwise. If b cannot be decompressed or its
+// decompressed size is larger than maxDecompressed, b is returned
+// with an error explaining why it was not decompressed.
+func decompress(b []byte) ([]byte, error) {
+	if !bytes.HasPrefix(b, []byte{0x1f, 0x8b}) {
+		return b, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return b, err
+	}
+	d, err := io.ReadAll(io.LimitReader(r, maxDecompressed+1))
+	if err != nil {
+		return b, err
+	}
+	if len(d) > maxDecompressed {
+		return b, fmt.Errorf("decompressed size larger than %d bytes", maxDecompressed)
+	}
+	return d, nil
+}
+
 // neverInText is the set of bytes never found in ASCII/UTF-8 text files.
 var neverInText = [256]bool{
 	// First row minus BEL BS TAB LF VT FF CR.
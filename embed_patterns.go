@@ -0,0 +1,203 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxEmbedBinary is the largest total size of binary files that may be
+// included by a //go:embed pattern that names a directory before it is
+// reported.
+const maxEmbedBinary = 1 << 20
+
+// embedDirective is the prefix of //go:embed directive comments.
+const embedDirective = "//go:embed"
+
+// embedPatternFindings returns findings for //go:embed patterns in f that
+// match no files or that name directories holding more than maxEmbedBinary
+// bytes of binary files. Patterns are resolved relative to the directory
+// holding the file at path.
+func embedPatternFindings(f *ast.File, path string) (map[*ast.Comment][]finding, error) {
+	found := make(map[*ast.Comment][]finding)
+	dir := filepath.Dir(path)
+	for _, g := range f.Comments {
+		for _, cm := range g.List {
+			if !strings.HasPrefix(cm.Text, embedDirective+" ") && !strings.HasPrefix(cm.Text, embedDirective+"\t") {
+				continue
+			}
+			for _, p := range embedPatterns(cm.Text, len(embedDirective)) {
+				files, binary, size, err := resolveEmbedPattern(dir, p.pattern)
+				if err != nil {
+					return nil, err
+				}
+				var note string
+				switch {
+				case files == 0:
+					note = "an embed pattern matching no files"
+				case size > maxEmbedBinary:
+					noun := "files"
+					if binary == 1 {
+						noun = "file"
+					}
+					note = fmt.Sprintf("an embed pattern including %d binary %s (%d bytes)", binary, noun, size)
+				default:
+					continue
+				}
+				found[cm] = append(found[cm], finding{
+					category: styleCategory,
+					word:     p.pattern,
+					span:     p.span,
+					note:     note,
+					rule:     embedPattern,
+				})
+			}
+		}
+	}
+	return found, nil
+}
+
+// embedPatternSpan is a //go:embed pattern and its span in the directive.
+type embedPatternSpan struct {
+	pattern string
+	span    span
+}
+
+// embedPatterns returns the patterns in the //go:embed directive text
+// starting at offset off. Patterns may be separated by spaces and may be
+// double-quoted or back-quoted Go string literals.
+func embedPatterns(text string, off int) []embedPatternSpan {
+	var patterns []embedPatternSpan
+	for off < len(text) {
+		rest := strings.TrimLeft(text[off:], " \t")
+		off = len(text) - len(rest)
+		if rest == "" {
+			break
+		}
+		var lit string
+		switch rest[0] {
+		case '"', '`':
+			var err error
+			lit, err = strconv.QuotedPrefix(rest)
+			if err != nil {
+				// Invalid directives are reported
+				// by the go tool.
+				return patterns
+			}
+		default:
+			lit = rest
+			if i := strings.IndexAny(rest, " \t"); i >= 0 {
+				lit = rest[:i]
+			}
+		}
+		pattern := lit
+		if lit[0] == '"' || lit[0] == '`' {
+			pattern, _ = strconv.Unquote(lit)
+		}
+		patterns = append(patterns, embedPatternSpan{
+			pattern: pattern,
+			span:    span{pos: off, end: off + len(lit)},
+		})
+		off += len(lit)
+	}
+	return patterns
+}
+
+// resolveEmbedPattern returns the number of files matched by the
+// //go:embed pattern relative to dir, and the number and total size of
+// binary files within directories matched by the pattern. Files are
+// included following the rules of the go tool, so files starting with
+// '.' or '_' within matched directories are only counted when the
+// pattern has an "all:" prefix, and nested modules are not searched.
+func resolveEmbedPattern(dir, pattern string) (files, binary int, size int64, err error) {
+	all := strings.HasPrefix(pattern, "all:")
+	pattern = strings.TrimPrefix(pattern, "all:")
+	matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+	if err != nil {
+		// Invalid patterns are reported by the go tool.
+		return 0, 0, 0, nil
+	}
+	for _, m := range matches {
+		fi, err := os.Stat(m)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if !fi.IsDir() {
+			if fi.Mode().IsRegular() {
+				files++
+			}
+			continue
+		}
+		err = filepath.WalkDir(m, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path != m {
+				name := d.Name()
+				if !all && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if d.IsDir() {
+					if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			files++
+			isBin, err := isBinaryFile(path)
+			if err != nil {
+				return err
+			}
+			if isBin {
+				fi, err := d.Info()
+				if err != nil {
+					return err
+				}
+				binary++
+				size += fi.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	return files, binary, size, nil
+}
+
+// isBinaryFile returns whether the start of the file at path holds bytes
+// that are never found in ASCII or UTF-8 text.
+func isBinaryFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	var buf [8 << 10]byte
+	n, err := io.ReadFull(f, buf[:])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	for _, b := range buf[:n] {
+		if neverInText[b] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
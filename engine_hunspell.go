@@ -0,0 +1,20 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build cgo
+// +build cgo
+
+package main
+
+import "github.com/kortschak/hunspell"
+
+// newHunspell returns a hunspell speller with the affix rules in the file
+// aff and the words in the file dic.
+func newHunspell(aff, dic string) (speller, error) {
+	s, err := hunspell.NewSpellPaths(aff, dic)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
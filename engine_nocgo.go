@@ -0,0 +1,21 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !cgo
+// +build !cgo
+
+package main
+
+import "errors"
+
+func init() {
+	// Hunspell requires cgo, so only the
+	// builtin engine is available.
+	defaults.Engine = builtinEngine
+}
+
+// newHunspell returns an error since hunspell is not available without cgo.
+func newHunspell(aff, dic string) (speller, error) {
+	return nil, errors.New("hunspell engine is not available in builds without cgo: use the builtin engine")
+}
@@ -18,11 +18,10 @@ import (
 var checkTagSpaces = map[string]bool{"json": true, "xml": true, "asn1": true}
 
 // extractStructTagWords parses the struct tag and collects all the words
-// in the struct tag. It returns nit if it is not in the canonical format,
-// which is a space-separated list of key:"value" settings. The value may
-// contain spaces.
-func extractStructTagWords(tag string) []string {
-	var kv []string
+// in the struct tag keys and values. It returns nil if it is not in the
+// canonical format, which is a space-separated list of key:"value" settings.
+// The value may contain spaces.
+func extractStructTagWords(tag string) (keys, values []string) {
 
 	// This code is based on the StructTag.Get code in package reflect.
 	n := 0
@@ -30,7 +29,7 @@ func extractStructTagWords(tag string) []string {
 		if n > 0 && tag != "" && tag[0] != ' ' {
 			// More restrictive than reflect, but catches likely mistakes
 			// like `x:"foo",y:"bar"`, which parses as `x:"foo" ,y:"bar"` with second key ",y".
-			return nil
+			return nil, nil
 		}
 		// Skip leading space.
 		i := 0
@@ -51,19 +50,19 @@ func extractStructTagWords(tag string) []string {
 			i++
 		}
 		if i == 0 {
-			return nil
+			return nil, nil
 		}
 		if i+1 >= len(tag) || tag[i] != ':' {
-			return nil
+			return nil, nil
 		}
 		if tag[i+1] != '"' {
-			return nil
+			return nil, nil
 		}
 		key := tag[:i]
 		tag = tag[i+1:]
 
 		// Get the struct tag key.
-		kv = append(kv, key)
+		keys = append(keys, key)
 
 		// Scan quoted string to find value.
 		i = 1
@@ -74,18 +73,18 @@ func extractStructTagWords(tag string) []string {
 			i++
 		}
 		if i >= len(tag) {
-			return nil
+			return nil, nil
 		}
 		qvalue := tag[:i+1]
 		tag = tag[i+1:]
 
 		value, err := strconv.Unquote(qvalue)
 		if err != nil {
-			return nil
+			return nil, nil
 		}
 
 		// Get all the struct tag values.
-		kv = append(kv, strings.Split(value, ",")...)
+		values = append(values, strings.Split(value, ",")...)
 
 		if !checkTagSpaces[key] {
 			continue
@@ -96,12 +95,12 @@ func extractStructTagWords(tag string) []string {
 			// If the first or last character in the XML tag is a space, it is
 			// suspicious.
 			if strings.Trim(value, " ") != value {
-				return nil
+				return nil, nil
 			}
 
 			// If there are multiple spaces, they are suspicious.
 			if strings.Count(value, " ") > 1 {
-				return nil
+				return nil, nil
 			}
 
 			// If there is no comma, skip the rest of the checks.
@@ -112,7 +111,7 @@ func extractStructTagWords(tag string) []string {
 
 			// If the character before a comma is a space, this is suspicious.
 			if comma > 0 && value[comma-1] == ' ' {
-				return nil
+				return nil, nil
 			}
 			value = value[comma+1:]
 		case "json":
@@ -125,9 +124,9 @@ func extractStructTagWords(tag string) []string {
 		}
 
 		if strings.IndexByte(value, ' ') >= 0 {
-			return nil
+			return nil, nil
 		}
 	}
 
-	return kv
+	return keys, values
 }
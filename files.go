@@ -0,0 +1,80 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fileQueries returns the package load patterns for the provided command
+// line arguments and comma-separated list of files, and a filter holding
+// the files to check. Arguments naming existing .go files are treated as
+// files to check. Each file is loaded as part of the packages holding it;
+// Go files with a file= query and other files, such as embedded files,
+// with their directory. If no files are named, the returned filter is nil
+// and the patterns are the provided arguments.
+func fileQueries(args []string, list string) (patterns []string, files changeFilter, err error) {
+	var paths []string
+	if list != "" {
+		paths = strings.Split(list, ",")
+	}
+	for _, a := range args {
+		if strings.HasSuffix(a, ".go") {
+			fi, err := os.Stat(a)
+			if err == nil && fi.Mode().IsRegular() {
+				paths = append(paths, a)
+				continue
+			}
+		}
+		patterns = append(patterns, a)
+	}
+	if len(paths) == 0 {
+		return args, nil, nil
+	}
+	files = make(changeFilter)
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, nil, err
+		}
+		fi, err := os.Stat(abs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil, nil, fmt.Errorf("%s is not a file", p)
+		}
+		if strings.HasSuffix(abs, ".go") {
+			patterns = append(patterns, "file="+abs)
+		} else {
+			patterns = append(patterns, filepath.Dir(abs))
+		}
+		files[wdRel(abs)] = []lineRange{{start: 1, end: math.MaxInt}}
+	}
+	return patterns, files, nil
+}
+
+// within returns the filter restricted to the files in files. If f is
+// nil, files is returned.
+func (f changeFilter) within(files changeFilter) changeFilter {
+	if f == nil {
+		return files
+	}
+	restricted := make(changeFilter)
+	for path, lines := range f {
+		if _, ok := files[path]; ok {
+			restricted[path] = lines
+		}
+	}
+	return restricted
+}
@@ -0,0 +1,121 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Finding categories.
+const (
+	spellingCategory  = "spelling"  // spellingCategory is the category of words not accepted by the dictionary.
+	styleCategory     = "style"     // styleCategory is the category of style rule findings.
+	referenceCategory = "reference" // referenceCategory is the category of unreachable URLs and dead or nonexistent references.
+)
+
+// finding is a single finding from any of the checker's subsystems.
+// Subsystems set the category, rule, severity, word, note and span of
+// a finding, and the checker locates it in the text holding it once the
+// text has been checked. Findings are the common form used by reports,
+// decision files and fixes.
+type finding struct {
+	category string
+	rule     string // rule is the style rule ID, empty for other categories.
+	severity level
+
+	word  string
+	note  string
+	where string
+
+	// pos is the position of the word. If the file
+	// has no line information, only the Filename
+	// and the Offset of the word within its node
+	// are valid.
+	pos       token.Position
+	generated bool
+
+	// confidence is the confidence that a
+	// spelling finding is a misspelling. It
+	// is zero if it was not calculated.
+	confidence float64
+
+	// text is the text of the node holding
+	// the finding and span is the span of
+	// the word within text.
+	text string
+	span span
+}
+
+// locate returns the finding f located in the checked text t, with its
+// position made relative to the file holding t. If warning is true, the
+// finding is at the warning severity level.
+func (c *checker) locate(f finding, t checkedText, warning bool) finding {
+	f.where = t.where
+	f.text = t.text
+	f.pos = t.pos
+	f.generated = c.generated[t.pos.Filename]
+	if warning {
+		f.severity = warningLevel
+	}
+	if f.pos.IsValid() {
+		f.pos.Column += f.span.pos
+		f.pos.Offset += f.span.pos
+	} else {
+		f.pos.Offset = f.span.pos
+	}
+	return f
+}
+
+// findings returns the checker's findings in file and offset order.
+func (c *checker) findings() []finding {
+	var findings []finding
+	for _, t := range c.checked {
+		findings = append(findings, t.findings...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		pi, pj := findings[i].pos, findings[j].pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+	return findings
+}
+
+// fingerprint returns a stable identifier for the finding that does not
+// depend on its line or column, so that findings can be matched across
+// changes that move them. It is derived from the file path relative to the
+// root of the module holding it, the category and rule, the word, the text
+// holding the word with white space normalised, and the number of earlier
+// occurrences of the word in that text. The path does not depend on the
+// working directory or on how paths are reported.
+func (f finding) fingerprint() string {
+	path := f.pos.Filename
+	if root, err := moduleRoot(filepath.Dir(path)); err == nil {
+		if r, err := filepath.Rel(root, path); err == nil {
+			path = r
+		}
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00", filepath.ToSlash(path), f.category, f.rule, f.word)
+	fmt.Fprintf(h, "%s\x00%d", strings.Join(strings.Fields(f.text), " "), strings.Count(f.text[:min(f.span.pos, len(f.text))], f.word))
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// position returns the file:line:column position of the finding relative
+// to the working directory, or file@offset if the file has no line
+// information.
+func (f finding) position() string {
+	if !f.pos.IsValid() {
+		return fmt.Sprintf("%v@%d", rel(f.pos.Filename), f.pos.Offset)
+	}
+	return fmt.Sprintf("%v:%d:%d", rel(f.pos.Filename), f.pos.Line, f.pos.Column)
+}
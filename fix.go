@@ -0,0 +1,251 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/sys/execabs"
+)
+
+// maxFixDistance is the largest edit distance between a misspelled word
+// and a suggestion that the fix command will apply.
+const maxFixDistance = 2
+
+// defaultFixMessage is the default commit message for fixes.
+const defaultFixMessage = "all: fix spelling"
+
+// applySafeFixes replaces misspelled words found by the checker with
+// their suggested correction when the correction is unambiguous and the
+// word is in prose, and reports the findings that were not fixed. If the
+// prompt option is set, the replacement for each spelling finding is read
+// from r after prompting on stdout instead. If the commit option is set,
+// files that are untracked or have uncommitted changes are not fixed. It
+// returns the lines that were changed in each file that was changed, and
+// a status that includes spellingError if any findings were not fixed.
+func (c *checker) applySafeFixes(r io.Reader) (fixed map[string][]int, status int) {
+	sc := bufio.NewScanner(r)
+	fixes := make(map[string][]decision)
+	uncommitted := make(uncommittedFiles)
+	for _, f := range c.findings() {
+		replacement, reason := c.safeFix(f)
+		reviewable := f.category == spellingCategory && f.pos.IsValid() && !f.generated
+		if reviewable && c.commit {
+			if why := uncommitted.reason(f.pos.Filename); why != "" {
+				fmt.Printf("%s: TODO: %q not fixed (%s)\n", f.position(), f.word, why)
+				status |= spellingError
+				continue
+			}
+		}
+		if reviewable && c.prompt {
+			var action string
+			action, replacement = c.promptFinding(sc, os.Stdout, f, replacement, false)
+			switch action {
+			case decideFix:
+				reason = ""
+			case promptEnd:
+				reason = "no response"
+			default:
+				reason = "skipped"
+			}
+		}
+		if reason != "" {
+			fmt.Printf("%s: TODO: %q not fixed (%s)\n", f.position(), f.word, reason)
+			status |= spellingError
+			continue
+		}
+		fmt.Printf("%s: fixed %q to %q\n", f.position(), f.word, replacement)
+		p := f.pos
+		fixes[p.Filename] = append(fixes[p.Filename], decision{
+			decision:    decideFix,
+			path:        p.Filename,
+			row:         p.Line,
+			col:         p.Column,
+			word:        f.word,
+			replacement: replacement,
+		})
+	}
+	fixed = make(map[string][]int)
+	for file, fix := range fixes {
+		lines, errs := fixFile(file, fix)
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+			status |= internalError
+		}
+		if len(lines) != 0 {
+			fixed[file] = lines
+		}
+	}
+	return fixed, status
+}
+
+// uncommittedFiles is a cache of the reasons that fixes to files cannot
+// be committed on their own.
+type uncommittedFiles map[string]string
+
+// reason returns why fixes to the file at path cannot be committed without
+// also committing other changes, or the empty string if they can. Fixes
+// cannot be committed on their own for files that are untracked or have
+// changes that have not been committed.
+func (u uncommittedFiles) reason(path string) string {
+	why, ok := u[path]
+	if ok {
+		return why
+	}
+	gitStatus := execabs.Command("git", "status", "--porcelain", "--", filepath.Base(path))
+	gitStatus.Dir = filepath.Dir(path)
+	out, err := gitStatus.Output()
+	switch {
+	case err != nil:
+		why = fmt.Sprintf("could not get git status: %v", err)
+	case bytes.HasPrefix(out, []byte("??")):
+		why = "untracked file"
+	case len(out) != 0:
+		why = "uncommitted changes"
+	}
+	u[path] = why
+	return why
+}
+
+// commitFixes formats the fixed lines of the Go source files in fixed with
+// gofmt and then commits the files with git using the provided message.
+// Changes to other files that have been staged are not included in the
+// commit.
+func commitFixes(fixed map[string][]int, message string) error {
+	files := make([]string, 0, len(fixed))
+	for path, lines := range fixed {
+		files = append(files, path)
+		if filepath.Ext(path) != ".go" {
+			continue
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		formatted, err := formatLines(b, lines)
+		if err != nil {
+			return fmt.Errorf("could not format %s: %v", path, err)
+		}
+		if bytes.Equal(b, formatted) {
+			continue
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(path, formatted, fi.Mode().Perm())
+		if err != nil {
+			return err
+		}
+	}
+	sort.Strings(files)
+	args := append([]string{"commit", "--quiet", "--message", message, "--"}, files...)
+	var stderr bytes.Buffer
+	gitCommit := execabs.Command("git", args...)
+	gitCommit.Stderr = &stderr
+	err := gitCommit.Run()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return nil
+}
+
+// formatLines returns src with the numbered lines replaced by the same
+// lines of src formatted with gofmt, leaving all other lines unaltered.
+// If formatting src changes its number of lines, the lines do not
+// correspond, so src is returned unaltered.
+func formatLines(src []byte, lines []int) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, err
+	}
+	orig := bytes.SplitAfter(src, []byte("\n"))
+	fmtd := bytes.SplitAfter(formatted, []byte("\n"))
+	if len(orig) != len(fmtd) {
+		return src, nil
+	}
+	for _, l := range lines {
+		if 1 <= l && l <= len(orig) {
+			orig[l-1] = fmtd[l-1]
+		}
+	}
+	return bytes.Join(orig, nil), nil
+}
+
+// safeFix returns the replacement for the word of the finding f if it
+// is safe to apply without review. A fix is safe when the finding is a
+// spelling finding in a non-generated file with line information, there
+// is exactly one suggestion within maxFixDistance edits of the word, and
+// the word is in prose rather than adjacent to code markers. If the fix
+// is not safe, the reason is returned.
+func (c *checker) safeFix(f finding) (replacement, reason string) {
+	switch {
+	case f.category != spellingCategory:
+		return "", f.note
+	case !f.pos.IsValid():
+		return "", "no line information"
+	case f.generated:
+		return "", "generated file"
+	case !inProse(f.text, f.word, f.span.pos):
+		return "", "not in prose"
+	}
+	c.useDictionary(f.pos.Filename)
+	word := strings.ToLower(f.word)
+	var close []string
+	for _, s := range c.suggestionsFor(f.word) {
+		if editDistance(word, strings.ToLower(s)) <= maxFixDistance {
+			close = append(close, s)
+		}
+	}
+	switch len(close) {
+	case 0:
+		return "", "no close suggestion"
+	case 1:
+		return close[0], ""
+	default:
+		return "", "ambiguous suggestions: " + strings.Join(close, ", ")
+	}
+}
+
+// inProse returns whether word at offset pos in text is in prose. Words
+// in prose are preceded by space or an opening parenthesis, and followed
+// by space, a closing parenthesis or sentence punctuation that is itself
+// followed by space. The end of a word's span may include the character
+// that follows it, so the word's length is used to find its end.
+func inProse(text, word string, pos int) bool {
+	if pos > 0 {
+		prev, _ := utf8.DecodeLastRuneInString(text[:pos])
+		if !unicode.IsSpace(prev) && prev != '(' {
+			return false
+		}
+	}
+	rest := text[pos+len(word):]
+	if rest == "" {
+		return true
+	}
+	next, n := utf8.DecodeRuneInString(rest)
+	if unicode.IsSpace(next) || next == ')' {
+		return true
+	}
+	if !strings.ContainsRune(".,;:!?", next) {
+		return false
+	}
+	rest = rest[n:]
+	if rest == "" {
+		return true
+	}
+	after, _ := utf8.DecodeRuneInString(rest)
+	return unicode.IsSpace(after) || after == ')'
+}
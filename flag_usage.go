@@ -0,0 +1,61 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// flagPackages is the set of import paths of packages with flag
+// registration functions and methods that take a usage argument.
+var flagPackages = map[string]bool{
+	"flag":                   true,
+	"github.com/spf13/pflag": true,
+}
+
+// flagUsages returns the string literals in usage arguments to flag
+// registration functions and methods called in f. Calls are identified
+// by the "usage" parameter of functions in the flag and pflag packages,
+// which include the flag sets used by cobra commands.
+func flagUsages(p *packages.Package, f *ast.File) []*ast.BasicLit {
+	var usages []*ast.BasicLit
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		var id *ast.Ident
+		switch fun := call.Fun.(type) {
+		case *ast.SelectorExpr:
+			id = fun.Sel
+		case *ast.Ident:
+			id = fun
+		default:
+			return true
+		}
+		fn, ok := p.TypesInfo.Uses[id].(*types.Func)
+		if !ok || fn.Pkg() == nil || !flagPackages[fn.Pkg().Path()] {
+			return true
+		}
+		params := fn.Type().(*types.Signature).Params()
+		for i := 0; i < params.Len() && i < len(call.Args); i++ {
+			if params.At(i).Name() != "usage" {
+				continue
+			}
+			ast.Inspect(call.Args[i], func(n ast.Node) bool {
+				if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					usages = append(usages, lit)
+				}
+				return true
+			})
+		}
+		return true
+	})
+	return usages
+}
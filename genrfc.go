@@ -0,0 +1,88 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+const rfcIndex = "https://www.rfc-editor.org/rfc-index.txt"
+
+// entry matches the first line of an entry in the RFC index.
+var entry = regexp.MustCompile(`^(\d{4,5}) (.*)$`)
+
+func main() {
+	resp, err := http.Get(rfcIndex)
+	if err != nil {
+		log.Fatalf("could not get RFC index: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("could not get RFC index: %s", resp.Status)
+	}
+
+	var (
+		max       int
+		notIssued []int
+	)
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		m := entry.FindStringSubmatch(sc.Text())
+		if m == nil {
+			continue
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			log.Fatalf("invalid RFC number: %v", err)
+		}
+		if m[2] == "Not Issued." {
+			notIssued = append(notIssued, n)
+		}
+		if n > max {
+			max = n
+		}
+	}
+	if err := sc.Err(); err != nil {
+		log.Fatalf("could not read RFC index: %v", err)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `// Code generated by genrfc.go from %s; DO NOT EDIT.
+
+package main
+
+// rfcIndexMax is the highest RFC number in the bundled index.
+const rfcIndexMax = %d
+
+// rfcNotIssued is the sorted list of RFC numbers in the bundled
+// index that were not issued.
+var rfcNotIssued = []int{`, rfcIndex, max)
+	for i, n := range notIssued {
+		if i%10 == 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "%d, ", n)
+	}
+	buf.WriteString("\n}\n")
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("could not format RFC index: %v", err)
+	}
+	err = os.WriteFile("rfc_index.go", b, 0o664)
+	if err != nil {
+		log.Fatalf("could not write RFC index: %v", err)
+	}
+}
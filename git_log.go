@@ -8,12 +8,11 @@ import (
 	"bufio"
 	"bytes"
 
-	"github.com/kortschak/hunspell"
 	"golang.org/x/sys/execabs"
 )
 
 // readGitLog adds author names and email addresses from git log.
-func readGitLog(spelling *hunspell.Spell) {
+func readGitLog(spelling speller, counts harvestCounts) {
 	cmd := execabs.Command("git", "log", "--format=%an %ae")
 	var buf bytes.Buffer
 	cmd.Stdout = &buf
@@ -25,10 +24,6 @@ func readGitLog(spelling *hunspell.Spell) {
 	var w words // Use our word scanner to retain parity.
 	sc.Split(w.ScanWords)
 	for sc.Scan() {
-		w := sc.Text()
-		if spelling.IsCorrect(w) {
-			continue
-		}
-		spelling.Add(w)
+		counts.add(spelling, gitLogSource, sc.Text())
 	}
 }
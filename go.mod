@@ -12,13 +12,13 @@ require (
 	github.com/kortschak/ct v0.0.0-20140325011614-7d86dffe6951
 	github.com/kortschak/hunspell v0.0.0-20220305030544-5d8374a03860
 	github.com/rogpeppe/go-internal v1.13.1
+	golang.org/x/mod v0.21.0
 	golang.org/x/sys v0.26.0
 	golang.org/x/tools v0.26.0
 	mvdan.cc/xurls/v2 v2.4.0
 )
 
 require (
-	golang.org/x/mod v0.21.0 // indirect
 	golang.org/x/sync v0.8.0 // indirect
 )
 
@@ -0,0 +1,60 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// Sources of words harvested from the checked code and its environment,
+// in report order.
+const (
+	pathSource      = "package paths"
+	identSource     = "identifiers"
+	importSource    = "imported symbols"
+	tagKeySource    = "struct tag keys"
+	tagValueSource  = "struct tag values"
+	directiveSource = "directives"
+	noteSource      = "note authors"
+	licenseSource   = "licenses"
+	gitLogSource    = "git log"
+)
+
+var harvestSources = []string{
+	pathSource,
+	identSource,
+	importSource,
+	tagKeySource,
+	tagValueSource,
+	directiveSource,
+	noteSource,
+	licenseSource,
+	gitLogSource,
+}
+
+// harvestCounts is the number of words added to a dictionary from each
+// harvest source.
+type harvestCounts map[string]int
+
+// add adds word to spelling if it is not already correctly spelled, and
+// counts the addition against source.
+func (h harvestCounts) add(spelling speller, source, word string) {
+	if spelling.IsCorrect(word) || !spelling.Add(word) {
+		return
+	}
+	h[source]++
+}
+
+// report writes the number of words added from each source to w.
+func (h harvestCounts) report(w io.Writer) {
+	for _, src := range harvestSources {
+		noun := "words"
+		if h[src] == 1 {
+			noun = "word"
+		}
+		fmt.Fprintf(w, "harvested %d %s from %s\n", h[src], noun, src)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// heuristicNames is the set of heuristic names that may be used in the
+// heuristic_order configuration, in their default order.
+var heuristicNames = []string{
+	"word_len",
+	"naked_hex",
+	"hex_rune",
+	"unit",
+	"rfc",
+	"initialisms",
+	"upper",
+	"single",
+	"numbers",
+	"mixed_alnum",
+	"patterns",
+}
+
+// heuristicName returns the configuration name of the heuristic h. A
+// heuristic without a configuration name is named by its type.
+func heuristicName(h heuristic) string {
+	switch h := h.(type) {
+	case wordLen:
+		return "word_len"
+	case isNakedHex:
+		return "naked_hex"
+	case isHexRune:
+		return "hex_rune"
+	case isUnit:
+		return "unit"
+	case isRFC:
+		return "rfc"
+	case initialisms:
+		return "initialisms"
+	case allUpper:
+		return "upper"
+	case isSingle:
+		return "single"
+	case *isNumber:
+		return "numbers"
+	case isMixedAlnum:
+		return "mixed_alnum"
+	case *patterns:
+		return "patterns"
+	case *profiledHeuristic:
+		return h.name
+	default:
+		return fmt.Sprintf("%T", h)
+	}
+}
+
+// orderHeuristics returns the heuristics reordered so that those named in
+// order are run first in the order given, followed by the remaining
+// heuristics in their original order. Names of heuristics that are not
+// enabled are ignored.
+func orderHeuristics(heuristics []heuristic, order []string) ([]heuristic, error) {
+	if len(order) == 0 {
+		return heuristics, nil
+	}
+	known := make(map[string]bool)
+	for _, n := range heuristicNames {
+		known[n] = true
+	}
+	byName := make(map[string]heuristic)
+	for _, h := range heuristics {
+		byName[heuristicName(h)] = h
+	}
+	ordered := make([]heuristic, 0, len(heuristics))
+	placed := make(map[string]bool)
+	for _, n := range order {
+		if !known[n] {
+			return nil, fmt.Errorf("unknown heuristic: %q", n)
+		}
+		if placed[n] {
+			return nil, fmt.Errorf("heuristic %q ordered more than once", n)
+		}
+		placed[n] = true
+		if h, ok := byName[n]; ok {
+			ordered = append(ordered, h)
+		}
+	}
+	for _, h := range heuristics {
+		if !placed[heuristicName(h)] {
+			ordered = append(ordered, h)
+		}
+	}
+	return ordered, nil
+}
+
+// profiledHeuristic is a heuristic that counts how often the wrapped
+// heuristic is used and accepts words, and the time spent in it.
+type profiledHeuristic struct {
+	heuristic
+	name     string
+	calls    int
+	accepted int
+	elapsed  time.Duration
+}
+
+// isAcceptable implements heuristic, recording the call.
+func (h *profiledHeuristic) isAcceptable(word string, partial bool) bool {
+	start := time.Now()
+	ok := h.heuristic.isAcceptable(word, partial)
+	h.elapsed += time.Since(start)
+	h.calls++
+	if ok {
+		h.accepted++
+	}
+	return ok
+}
+
+// profileHeuristics returns the heuristics wrapped so that their use is
+// recorded.
+func profileHeuristics(heuristics []heuristic) []heuristic {
+	profiled := make([]heuristic, len(heuristics))
+	for i, h := range heuristics {
+		profiled[i] = &profiledHeuristic{heuristic: h, name: heuristicName(h)}
+	}
+	return profiled
+}
+
+// reportHeuristics writes the recorded use of each profiled heuristic to
+// w in the order the heuristics are run.
+func (c *checker) reportHeuristics(w io.Writer) {
+	for _, h := range c.heuristics {
+		p, ok := h.(*profiledHeuristic)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "heuristic %s: %d calls, %d accepted, %v\n", p.name, p.calls, p.accepted, p.elapsed)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"go/scanner"
 	"go/token"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
@@ -21,17 +22,30 @@ type heuristic interface {
 	// partial is true, the word is a portion of a whole word that has
 	// been split.
 	isAcceptable(word string, partial bool) bool
+
+	// reason returns a description of why the heuristic
+	// accepted word for reporting suppressed words.
+	reason(word string) string
 }
 
 // wordLen is a word length heuristic.
 type wordLen struct {
-	max int
+	min, max int
 }
 
-// isAcceptable returns whether the query word is over the maximum word
-// length to consider.
+// isAcceptable returns whether the query word is under the minimum or
+// over the maximum word length to consider. The minimum length is
+// measured in runes.
 func (h wordLen) isAcceptable(word string, _ bool) bool {
-	return h.max > 0 && len(word) > h.max
+	return (h.max > 0 && len(word) > h.max) || (h.min > 0 && utf8.RuneCountInString(word) < h.min)
+}
+
+// reason implements heuristic.
+func (h wordLen) reason(word string) string {
+	if h.max > 0 && len(word) > h.max {
+		return "max word length"
+	}
+	return "min word length"
 }
 
 // allUpper is a heuristic that accepts all-uppercase words.
@@ -51,6 +65,54 @@ func (allUpper) isAcceptable(word string, _ bool) bool {
 	return true
 }
 
+// reason implements heuristic.
+func (allUpper) reason(string) string { return "all uppercase" }
+
+// initialisms is a heuristic that accepts mixed-case initialisms and
+// acronyms, and plurals of initialisms.
+type initialisms struct {
+	known map[string]bool
+
+	// upperPlurals indicates that plurals of
+	// all-uppercase words are accepted.
+	upperPlurals bool
+}
+
+// newInitialisms returns a new initialisms heuristic accepting the
+// provided known mixed-case initialisms. If upperPlurals is true the
+// plurals of all-uppercase words are also accepted.
+func newInitialisms(known []string, upperPlurals bool) initialisms {
+	h := initialisms{known: make(map[string]bool, len(known)), upperPlurals: upperPlurals}
+	for _, w := range known {
+		h.known[w] = true
+	}
+	return h
+}
+
+// isAcceptable returns whether word is a known initialism optionally
+// followed by a version number or a plural 's', for example "OAuth2"
+// and "OAuths", or, when upper-case plurals are accepted, whether the
+// word is the plural of an all-uppercase initialism, for example "IDs"
+// and "APIs".
+func (h initialisms) isAcceptable(word string, _ bool) bool {
+	base := strings.TrimSuffix(word, "s")
+	if h.known[base] || h.known[strings.TrimRightFunc(base, unicode.IsDigit)] {
+		return true
+	}
+	if !h.upperPlurals || base == word || utf8.RuneCountInString(base) < 2 {
+		return false
+	}
+	for _, r := range base {
+		if !unicode.IsUpper(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// reason implements heuristic.
+func (initialisms) reason(string) string { return "initialism" }
+
 // isSingle is a heuristic that accepts single-rune words.
 type isSingle struct{}
 
@@ -59,6 +121,30 @@ func (isSingle) isAcceptable(word string, _ bool) bool {
 	return utf8.RuneCountInString(word) == 1
 }
 
+// reason implements heuristic.
+func (isSingle) reason(string) string { return "single letter" }
+
+// isMixedAlnum is a heuristic that accepts words that contain both
+// letters and digits.
+type isMixedAlnum struct{}
+
+// isAcceptable returns whether the query word contains at least one
+// letter and at least one digit.
+func (isMixedAlnum) isAcceptable(word string, _ bool) bool {
+	var letter, digit bool
+	for _, r := range word {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+		if letter && digit {
+			return true
+		}
+	}
+	return false
+}
+
+// reason implements heuristic.
+func (isMixedAlnum) reason(string) string { return "mixed alphanumeric" }
+
 // isNakedHex is a heuristic that accepts hex numbers as valid words
 type isNakedHex struct {
 	// minLen is a minimum length that will be accepted. This
@@ -72,6 +158,9 @@ func (h isNakedHex) isAcceptable(word string, _ bool) bool {
 	return h.minLen != 0 && len(word) >= h.minLen && isHex(word)
 }
 
+// reason implements heuristic.
+func (isNakedHex) reason(string) string { return "naked hex" }
+
 // isNumber is a heuristic that accepts all Go syntax numbers as
 // valid words.
 type isNumber struct {
@@ -90,6 +179,9 @@ func (h *isNumber) isAcceptable(word string, _ bool) bool {
 	return !errored && lit == word && (tok == token.INT || tok == token.FLOAT || tok == token.IMAG)
 }
 
+// reason implements heuristic.
+func (*isNumber) reason(string) string { return "number" }
+
 // isHexRune is a heuristic that accepts Go rune literal syntax as a valid
 // word.
 type isHexRune struct{}
@@ -120,6 +212,26 @@ func (isHexRune) isAcceptable(word string, _ bool) bool {
 	}
 }
 
+// reason implements heuristic.
+func (isHexRune) reason(string) string { return "rune literal" }
+
+// isRFC is a heuristic that accepts IETF RFC references such as "rfc7231"
+// and the words "RFC" and "RFCs" in any case.
+type isRFC struct{}
+
+// isAcceptable returns whether word is an RFC reference. If partial is true,
+// word is not an RFC reference as it would have been directly adjacent to
+// other characters.
+func (isRFC) isAcceptable(word string, partial bool) bool {
+	return !partial && rfcWord.MatchString(word)
+}
+
+// reason implements heuristic.
+func (isRFC) reason(string) string { return "RFC reference" }
+
+// rfcWord matches RFC references and the words RFC and RFCs.
+var rfcWord = regexp.MustCompile(`^(?i:rfcs?|rfc\d+)$`)
+
 // isUnit is a heuristic that accepts quantities with units as valid words.
 type isUnit struct{}
 
@@ -147,6 +259,9 @@ func (isUnit) isAcceptable(word string, partial bool) bool {
 	return false
 }
 
+// reason implements heuristic.
+func (isUnit) reason(string) string { return "quantity with unit" }
+
 // knownUnits is the set of units we check for. Add more as they are
 // identified as problems.
 var knownUnits = []string{
@@ -162,18 +277,38 @@ var knownUnits = []string{
 }
 
 // patterns is a heuristic based on user-provided regular expressions.
-type patterns []*regexp.Regexp
+type patterns struct {
+	res []*regexp.Regexp
+
+	// rules holds the affix rules to record
+	// words accepted by the corresponding
+	// expression with. A nil element indicates
+	// that accepted words are not recorded.
+	rules []*string
+}
 
 // newPatterns returns a new patterns compiled from the provided
-// expressions.
-func newPatterns(exprs []string) (patterns, error) {
-	p := make([]*regexp.Regexp, len(exprs))
+// expressions. Words accepted by expressions that have an entry in rules
+// are given dictionary entries with the associated affix rules.
+func newPatterns(exprs []string, rules map[string]string) (*patterns, error) {
+	p := &patterns{
+		res:   make([]*regexp.Regexp, len(exprs)),
+		rules: make([]*string, len(exprs)),
+	}
 	var err error
 	for i, re := range exprs {
-		p[i], err = regexp.Compile(re)
+		p.res[i], err = regexp.Compile(re)
 		if err != nil {
 			return nil, fmt.Errorf("could not construct pattern heuristic: %w", err)
 		}
+		if r, ok := rules[re]; ok {
+			p.rules[i] = &r
+		}
+	}
+	for re := range rules {
+		if !slices.Contains(exprs, re) {
+			return nil, fmt.Errorf("affix rules for unknown pattern: %q", re)
+		}
 	}
 	return p, nil
 }
@@ -182,11 +317,11 @@ func newPatterns(exprs []string) (patterns, error) {
 // in the patterns heuristic. If partial is true no regexp is tried and
 // false is returned. If partial matches are required, they should be
 // encoded into the patterns.
-func (h patterns) isAcceptable(word string, partial bool) bool {
+func (h *patterns) isAcceptable(word string, partial bool) bool {
 	if partial {
 		return false
 	}
-	for _, p := range h {
+	for _, p := range h.res {
 		if p.MatchString(word) {
 			return true
 		}
@@ -194,6 +329,61 @@ func (h patterns) isAcceptable(word string, partial bool) bool {
 	return false
 }
 
+// entry returns the dictionary entry for word if the first regular
+// expression matching it has affix rules for recording accepted words.
+func (h *patterns) entry(word string) (string, bool) {
+	for i, p := range h.res {
+		if !p.MatchString(word) {
+			continue
+		}
+		switch r := h.rules[i]; {
+		case r == nil:
+			return "", false
+		case *r == "":
+			return word, true
+		default:
+			return word + "/" + *r, true
+		}
+	}
+	return "", false
+}
+
+// reason returns the first regular expression in the patterns heuristic
+// matching word.
+func (h *patterns) reason(word string) string {
+	for _, p := range h.res {
+		if p.MatchString(word) {
+			return fmt.Sprintf("pattern %q", p)
+		}
+	}
+	return "pattern"
+}
+
+// nameContext matches text that introduces the names of people, for
+// example "written by" or "thanks to", followed by any capitalised words
+// and list separators preceding the word being considered.
+var nameContext = regexp.MustCompile(`(?i:\bby|\bthanks to|\bcourtesy of|\bcredit to|\bauthors?:)\s+(?:(?:\p{Lu}[\p{L}'.-]*|and|&)[,\s]+){0,8}$`)
+
+// copyrightLine matches the start of a copyright notice line.
+var copyrightLine = regexp.MustCompile(`^(?i:copyright\b|©|\(c\))`)
+
+// isPersonName returns whether the word at s in the comment or string
+// text is capitalised and in a context where the names of people are
+// expected: following "by", "thanks to", "courtesy of", "credit to" or
+// "author:", possibly as part of a list of names, or in a copyright
+// notice line.
+func isPersonName(text string, s span, word string) bool {
+	r, size := utf8.DecodeRuneInString(word)
+	if !unicode.IsUpper(r) || size == len(word) || strings.ToLower(word[size:]) != word[size:] {
+		return false
+	}
+	line := text[strings.LastIndexByte(text[:s.pos], '\n')+1 : s.pos]
+	if copyrightLine.MatchString(trimCommentMarkers(line)) {
+		return true
+	}
+	return nameContext.MatchString(line)
+}
+
 // isHex returns whether all bytes of s are hex digits.
 func isHex(s string) bool {
 	for _, b := range s {
@@ -0,0 +1,103 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// typographic is the built-in set of input conversions used to condition
+// words before checking. It maps typographic characters commonly found in
+// text copied from rendered documents to their plain text equivalents.
+var typographic = []string{
+	// Ligatures.
+	"ﬀ", "ff",
+	"ﬁ", "fi",
+	"ﬂ", "fl",
+	"ﬃ", "ffi",
+	"ﬄ", "ffl",
+	"ﬅ", "st",
+	"ﬆ", "st",
+
+	// Quotation marks used as apostrophes.
+	"’", "'",
+	"‘", "'",
+	"ʼ", "'",
+
+	// Invisible characters.
+	"\u00ad", "", // Soft hyphen.
+	"\u200b", "", // Zero width space.
+	"\u200c", "", // Zero width non-joiner.
+	"\u200d", "", // Zero width joiner.
+	"\u2060", "", // Word joiner.
+	"\ufeff", "", // Zero width no-break space.
+
+	// Dashes.
+	"\u2010", "-", // Hyphen.
+	"\u2011", "-", // Non-breaking hyphen.
+}
+
+// newConditioner returns a replacer that applies the built-in typographic
+// conversions and, if iconv is true, any ICONV input conversions defined
+// in the hunspell affix file at aff. Conversions in the affix file take
+// precedence over the built-in conversions. The hunspell engine applies
+// ICONV conversions itself, so iconv should only be true for engines that
+// do not.
+func newConditioner(aff string, iconv bool) (*strings.Replacer, error) {
+	if !iconv {
+		return strings.NewReplacer(typographic...), nil
+	}
+	pairs, err := readIconv(aff)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReplacer(append(pairs, typographic...)...), nil
+}
+
+// readIconv returns the ICONV input conversion pairs defined in the hunspell
+// affix file at path. Only UTF-8 encoded affix files are read; the pairs
+// from affix files in other encodings are ignored.
+func readIconv(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		pairs []string
+		n     = -1
+	)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "SET":
+			if !strings.EqualFold(fields[1], "UTF-8") {
+				return nil, nil
+			}
+		case "ICONV":
+			if n < 0 {
+				// The first ICONV line holds the count.
+				n, err = strconv.Atoi(fields[1])
+				if err != nil {
+					n = 0
+				}
+				continue
+			}
+			if len(fields) < 3 || len(pairs) >= 2*n {
+				continue
+			}
+			pairs = append(pairs, fields[1], fields[2])
+		}
+	}
+	return pairs, sc.Err()
+}
@@ -0,0 +1,269 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ignoreFile is the name of files holding gitignore-style patterns for
+// files and directories that should not be checked.
+const ignoreFile = ".gospelignore"
+
+// ignorer reports whether files are excluded from checking by the
+// patterns in .gospelignore files. Ignore files are read from the
+// directory holding a file and each of its parents up to the root of
+// its module. As with gitignore, patterns in files in deeper directories
+// take precedence over those in shallower directories, and later patterns
+// in a file take precedence over earlier patterns.
+type ignorer struct {
+	// roots is the set of module roots.
+	roots map[string]bool
+
+	// rules is a cache of the rules found
+	// in each directory.
+	rules map[string][]ignoreRule
+
+	// attrs is a cache of the git attributes
+	// rules found in each directory.
+	attrs map[string][]attrRule
+}
+
+// newIgnorer returns a new ignorer for files in the modules of the provided
+// packages.
+func newIgnorer(pkgs []*packages.Package) *ignorer {
+	ig := &ignorer{
+		roots: make(map[string]bool),
+		rules: make(map[string][]ignoreRule),
+		attrs: make(map[string][]attrRule),
+	}
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		if p.Module != nil && p.Module.Dir != "" {
+			ig.roots[filepath.Clean(p.Module.Dir)] = true
+		}
+	})
+	return ig
+}
+
+// isIgnored returns whether the file at path is excluded from checking.
+func (ig *ignorer) isIgnored(path string) (bool, error) {
+	if ig == nil {
+		return false, nil
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	dirs := ig.dirs(path)
+
+	var ignored bool
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rules, err := ig.rulesFor(dirs[i])
+		if err != nil {
+			return false, err
+		}
+		for _, r := range rules {
+			if r.matches(path) {
+				ignored = !r.negate
+			}
+		}
+	}
+	return ignored, nil
+}
+
+// dirs returns the directories holding the file at the absolute path
+// and each of its parents up to the root of its module, deepest first.
+func (ig *ignorer) dirs(path string) []string {
+	var dirs []string
+	for dir := filepath.Dir(path); ; {
+		dirs = append(dirs, dir)
+		if ig.roots[dir] {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// Not in a known module, so only
+			// consider the file's directory.
+			dirs = dirs[:1]
+			break
+		}
+		dir = parent
+	}
+	return dirs
+}
+
+// rulesFor returns the ignore rules held in the ignore file in dir.
+func (ig *ignorer) rulesFor(dir string) ([]ignoreRule, error) {
+	rules, ok := ig.rules[dir]
+	if ok {
+		return rules, nil
+	}
+	rules, err := readIgnoreFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	ig.rules[dir] = rules
+	return rules, nil
+}
+
+// readIgnoreFile returns the rules in the ignore file in dir. It returns
+// no rules and no error if the file does not exist.
+func readIgnoreFile(dir string) ([]ignoreRule, error) {
+	path := filepath.Join(dir, ignoreFile)
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var rules []ignoreRule
+	sc := bufio.NewScanner(f)
+	for i := 1; sc.Scan(); i++ {
+		r, ok, err := parseIgnoreRule(dir, sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%w at %s:%d", err, path, i)
+		}
+		if ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules, sc.Err()
+}
+
+// ignoreRule is a single gitignore-style pattern.
+type ignoreRule struct {
+	// base is the directory holding the
+	// ignore file the rule was read from.
+	base string
+
+	// pattern is the compiled pattern.
+	pattern *regexp.Regexp
+
+	// negate indicates the rule re-includes
+	// files excluded by earlier rules.
+	negate bool
+	// dirOnly indicates the rule only
+	// matches directories.
+	dirOnly bool
+	// anchored indicates the rule matches
+	// paths relative to base rather than
+	// any final path element.
+	anchored bool
+}
+
+// parseIgnoreRule returns the rule in the provided ignore file line. If the
+// line is blank or a comment, ok is false.
+func parseIgnoreRule(base, line string) (r ignoreRule, ok bool, err error) {
+	line = strings.TrimRight(line, " \t")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false, nil
+	}
+	r.base = base
+	switch {
+	case strings.HasPrefix(line, "!"):
+		r.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\`):
+		// Escaped leading # or !.
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false, nil
+	}
+	r.pattern, err = regexp.Compile(globExpr(line))
+	if err != nil {
+		return ignoreRule{}, false, fmt.Errorf("invalid ignore pattern %q", line)
+	}
+	return r, true, nil
+}
+
+// matches returns whether the rule matches the file at the absolute path
+// or any of its parent directories below the rule's base.
+func (r ignoreRule) matches(path string) bool {
+	rel, err := filepath.Rel(r.base, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	elems := strings.Split(filepath.ToSlash(rel), "/")
+	for i := range elems {
+		isDir := i < len(elems)-1
+		if r.dirOnly && !isDir {
+			continue
+		}
+		candidate := elems[i]
+		if r.anchored {
+			candidate = strings.Join(elems[:i+1], "/")
+		}
+		if r.pattern.MatchString(candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// globExpr returns a regular expression equivalent to the gitignore glob.
+func globExpr(glob string) string {
+	var buf strings.Builder
+	buf.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**") {
+				switch {
+				case strings.HasPrefix(glob[i:], "**/"):
+					buf.WriteString("(?:.*/)?")
+					i += 2
+				default:
+					buf.WriteString(".*")
+					i++
+				}
+				continue
+			}
+			buf.WriteString("[^/]*")
+		case '?':
+			buf.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				buf.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			buf.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				buf.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	buf.WriteString("$")
+	return buf.String()
+}
@@ -0,0 +1,46 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// loadIgnoredFiles returns packages for the non-test Go source files of
+// pkgs that are excluded by build constraints, such as generator programs
+// marked with a "//go:build ignore" constraint or files for other
+// platforms. Each file is loaded as a package on its own since naming a
+// file explicitly overrides its build constraints, so references to
+// declarations in other files are not resolved. Errors in the loaded
+// packages are ignored.
+func loadIgnoredFiles(cfg *packages.Config, pkgs []*packages.Package) ([]*packages.Package, error) {
+	seen := make(map[string]bool)
+	var ignored []*packages.Package
+	for _, p := range pkgs {
+		for _, path := range p.IgnoredFiles {
+			if filepath.Ext(path) != ".go" || strings.HasSuffix(path, "_test.go") || seen[path] {
+				continue
+			}
+			seen[path] = true
+			loaded, err := packages.Load(cfg, path)
+			if err != nil {
+				return nil, err
+			}
+			for _, l := range loaded {
+				if l.Module == nil {
+					// Ad hoc packages have no module, but
+					// the file belongs to the module of the
+					// package it was ignored by.
+					l.Module = p.Module
+				}
+			}
+			ignored = append(ignored, loaded...)
+		}
+	}
+	return ignored, nil
+}
@@ -0,0 +1,141 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// issueRef is a reference to an issue or pull request in a GitHub
+// repository.
+type issueRef struct {
+	span   span
+	repo   string // repo is the owner/name of the repository.
+	number string
+}
+
+// issueRefs matches issue references in text. It matches Go issue
+// tracker URLs, owner/repo#123 references and bare #123 references.
+// Bare references must not be preceded by a word character.
+var issueRefs = regexp.MustCompile(`(?:https?://)?(?:go\.dev|golang\.org)/issues?/(\d+)\b|\b([\w.-]+/[\w.-]+)#(\d+)\b|(?:^|[^\w/&])#(\d+)\b`)
+
+// findIssueRefs returns the issue references in text. Bare references
+// are resolved against the repository, repo, which may be empty in which
+// case bare references are not returned.
+func findIssueRefs(text, repo string) []issueRef {
+	var refs []issueRef
+	for _, m := range issueRefs.FindAllStringSubmatchIndex(text, -1) {
+		switch {
+		case m[2] >= 0:
+			refs = append(refs, issueRef{
+				span:   span{pos: m[0], end: m[1]},
+				repo:   "golang/go",
+				number: text[m[2]:m[3]],
+			})
+		case m[4] >= 0:
+			refs = append(refs, issueRef{
+				span:   span{pos: m[0], end: m[1]},
+				repo:   text[m[4]:m[5]],
+				number: text[m[6]:m[7]],
+			})
+		case m[8] >= 0 && repo != "":
+			// Exclude the preceding character.
+			refs = append(refs, issueRef{
+				span:   span{pos: m[8] - 1, end: m[1]},
+				repo:   repo,
+				number: text[m[8]:m[9]],
+			})
+		}
+	}
+	return refs
+}
+
+// githubRepo returns the owner/name of the GitHub repository holding the
+// first module in pkgs, or the empty string if the module is not hosted
+// on GitHub.
+func githubRepo(pkgs []*packages.Package) string {
+	for _, p := range pkgs {
+		if p.Module == nil {
+			continue
+		}
+		path, ok := strings.CutPrefix(p.Module.Path, "github.com/")
+		if !ok {
+			return ""
+		}
+		elems := strings.SplitN(path, "/", 3)
+		if len(elems) < 2 {
+			return ""
+		}
+		return elems[0] + "/" + elems[1]
+	}
+	return ""
+}
+
+// issueAPI is the GitHub API endpoint for issues. Pull requests are
+// also issues as far as the API is concerned.
+const issueAPI = "https://api.github.com/repos/%s/issues/%s"
+
+// confirmIssues fills and returns dst with a list of issue references
+// that do not exist with the HTTP status or error reasons included.
+// If the GITHUB_TOKEN environment variable is set, it is used to
+// authenticate API requests.
+func (c *checker) confirmIssues(dst []finding, text string, node ast.Node) []finding {
+	for _, ref := range findIssueRefs(text, c.issueRepo) {
+		if !c.changeFilter.isInChange(node.Pos()+token.Pos(ref.span.pos), c.fileset) {
+			continue
+		}
+		key := ref.repo + "#" + ref.number
+		note, ok := c.issues[key]
+		if !ok {
+			note = issueStatus(ref)
+			c.issues[key] = note
+		}
+		if note == "" {
+			continue
+		}
+		dst = append(dst, finding{
+			category: referenceCategory,
+			word:     text[ref.span.pos:ref.span.end],
+			span:     ref.span,
+			note:     note,
+		})
+	}
+	return dst
+}
+
+// issueStatus returns a note describing why the referenced issue could
+// not be confirmed to exist, or the empty string if it exists. Issues
+// are only reported as dead if the API reports them as not found or
+// gone, so rate limiting does not result in false positives.
+func issueStatus(ref issueRef) string {
+	req, err := http.NewRequest(http.MethodHead, fmt.Sprintf(issueAPI, ref.repo, ref.number), nil)
+	if err != nil {
+		return fmt.Sprintf("unreachable issue (%v)", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if tok := os.Getenv("GITHUB_TOKEN"); tok != "" {
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Sprintf("unreachable issue (%v)", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return fmt.Sprintf("a dead issue reference (%v)", resp.Status)
+	}
+	return ""
+}
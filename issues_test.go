@@ -0,0 +1,60 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var issueRefTests = []struct {
+	text string
+	repo string
+	want []issueRef
+}{
+	{
+		text: "// See go.dev/issue/1234 and https://golang.org/issues/99.",
+		repo: "",
+		want: []issueRef{
+			{span: span{pos: 7, end: 24}, repo: "golang/go", number: "1234"},
+			{span: span{pos: 29, end: 57}, repo: "golang/go", number: "99"},
+		},
+	},
+	{
+		text: "// Fixed in kortschak/gospel#12, see #34 (not &#35; or a#56).",
+		repo: "",
+		want: []issueRef{
+			{span: span{pos: 12, end: 31}, repo: "kortschak/gospel", number: "12"},
+		},
+	},
+	{
+		text: "// Fixed in kortschak/gospel#12, see #34 (not &#35; or a#56).",
+		repo: "owner/repo",
+		want: []issueRef{
+			{span: span{pos: 12, end: 31}, repo: "kortschak/gospel", number: "12"},
+			{span: span{pos: 37, end: 40}, repo: "owner/repo", number: "34"},
+		},
+	},
+	{
+		text: "#7 at the start.",
+		repo: "owner/repo",
+		want: []issueRef{
+			{span: span{pos: 0, end: 2}, repo: "owner/repo", number: "7"},
+		},
+	},
+}
+
+func TestFindIssueRefs(t *testing.T) {
+	for _, test := range issueRefTests {
+		got := findIssueRefs(test.text, test.repo)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected result for %q with repo %q\n%s",
+				test.text, test.repo, cmp.Diff(got, test.want, cmp.AllowUnexported(issueRef{}, span{})),
+			)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maskJSON returns the Go string literal lit with all text other than JSON
+// string values replaced with spaces if the literal holds a JSON object or
+// array. If the literal does not hold JSON, lit is returned unaltered and
+// ok is false.
+func maskJSON(lit string) (masked string, ok bool) {
+	text, offsets, err := unquoteOffsets(lit)
+	if err != nil {
+		return lit, false
+	}
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') || !json.Valid([]byte(text)) {
+		return lit, false
+	}
+
+	keep := make([]bool, len(lit))
+	for _, v := range jsonStringValues(text) {
+		for i := v.pos; i < v.end; i++ {
+			end := len(lit) - 1 // Exclude closing quote.
+			if i+1 < len(offsets) {
+				end = offsets[i+1]
+			}
+			for j := offsets[i]; j < end; j++ {
+				keep[j] = true
+			}
+		}
+	}
+	b := []byte(lit)
+	for i, k := range keep {
+		if !k && b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+	return string(b), true
+}
+
+// jsonStringValues returns the spans of the contents of string values in
+// the valid JSON text. Object keys are not included.
+func jsonStringValues(text string) []span {
+	var values []span
+	for i := 0; i < len(text); i++ {
+		if text[i] != '"' {
+			continue
+		}
+		start := i + 1
+		for i++; i < len(text) && text[i] != '"'; i++ {
+			if text[i] == '\\' {
+				i++
+			}
+		}
+		end := i
+
+		// A string followed by a colon is an object key.
+		rest := strings.TrimLeft(text[i+1:], " \t\r\n")
+		if strings.HasPrefix(rest, ":") {
+			continue
+		}
+		values = append(values, span{pos: start, end: end})
+	}
+	return values
+}
+
+// unquoteOffsets returns the value of the Go string literal lit and the
+// offset in lit of the start of the source of each byte of the value.
+func unquoteOffsets(lit string) (text string, offsets []int, err error) {
+	if len(lit) < 2 || lit[0] != lit[len(lit)-1] {
+		return "", nil, errors.New("invalid string literal")
+	}
+	switch lit[0] {
+	case '`':
+		text = lit[1 : len(lit)-1]
+		offsets = make([]int, len(text))
+		for i := range offsets {
+			offsets[i] = i + 1
+		}
+		return text, offsets, nil
+	case '"':
+		s := lit[1 : len(lit)-1]
+		buf := make([]byte, 0, len(s))
+		pos := 1
+		for len(s) != 0 {
+			r, multibyte, tail, err := strconv.UnquoteChar(s, '"')
+			if err != nil {
+				return "", nil, err
+			}
+			n := len(buf)
+			if r < utf8.RuneSelf || !multibyte {
+				buf = append(buf, byte(r))
+			} else {
+				buf = utf8.AppendRune(buf, r)
+			}
+			for ; n < len(buf); n++ {
+				offsets = append(offsets, pos)
+			}
+			pos += len(s) - len(tail)
+			s = tail
+		}
+		return string(buf), offsets, nil
+	default:
+		return "", nil, errors.New("invalid string literal")
+	}
+}
@@ -13,12 +13,11 @@ import (
 	"unicode"
 
 	"github.com/google/licensecheck"
-	"github.com/kortschak/hunspell"
 )
 
 // readLicenses adds words from licenses under root that satisfy the licensecheck
 // threshold provided.
-func readLicenses(spelling *hunspell.Spell, root string, thresh float64) error {
+func readLicenses(spelling speller, root string, thresh float64, counts harvestCounts) error {
 	texts, err := licenses(root, thresh)
 	if err != nil {
 		return err
@@ -28,11 +27,7 @@ func readLicenses(spelling *hunspell.Spell, root string, thresh float64) error {
 		var w words // Use our word scanner to retain parity.
 		sc.Split(w.ScanWords)
 		for sc.Scan() {
-			w := quietly(sc.Text())
-			if spelling.IsCorrect(w) {
-				continue
-			}
-			spelling.Add(w)
+			counts.add(spelling, licenseSource, quietly(sc.Text()))
 		}
 	}
 	return nil
@@ -0,0 +1,182 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// lintCheck is the check name used to refer to gospel in linter
+// directives.
+const lintCheck = "spelling"
+
+// lintDirective is a //lint:ignore or //lint:file-ignore directive naming
+// the spelling check, following the staticcheck directive syntax.
+type lintDirective struct {
+	pos token.Position
+
+	// start and end are the offsets of the
+	// text in the file that the directive
+	// applies to.
+	start, end int
+
+	// report indicates whether the directive
+	// should be reported if it does not
+	// suppress any findings. Directives outside
+	// the changes being checked are not.
+	report bool
+	used   bool
+}
+
+// lintDirectiveRx matches staticcheck linter directives, capturing the
+// kind of directive, the comma-separated list of checks and the reason.
+var lintDirectiveRx = regexp.MustCompile(`^//lint:(ignore|file-ignore)(?:[ \t]+(\S+))?(?:[ \t]+(.*))?$`)
+
+// isLintDirective returns whether the comment line is a staticcheck
+// linter directive.
+func isLintDirective(line string) bool {
+	return strings.HasPrefix(line, "//lint:")
+}
+
+// noteLintDirectives records the linter directives in f naming the
+// spelling check. Only directives in the changes being checked are
+// reported if they are malformed or unused.
+func (c *checker) noteLintDirectives(fset *token.FileSet, f *ast.File) {
+	dirs, malformed := lintDirectives(fset, f)
+	for _, d := range dirs {
+		d.report = c.changeFilter.hasLine(d.pos.Filename, d.pos.Line)
+	}
+	if len(dirs) != 0 {
+		c.lintDirectives[fset.Position(f.Pos()).Filename] = dirs
+	}
+	for _, p := range malformed {
+		if c.changeFilter.hasLine(p.Filename, p.Line) {
+			c.malformedDirectives = append(c.malformedDirectives, p)
+		}
+	}
+}
+
+// lintDirectives returns the linter directives in f that name the spelling
+// check, and the positions of directives naming the check that are
+// malformed. A //lint:file-ignore directive applies to the whole file. A
+// //lint:ignore directive applies to its comment group and the outermost
+// syntax node starting on the line after the group.
+func lintDirectives(fset *token.FileSet, f *ast.File) (dirs []*lintDirective, malformed []token.Position) {
+	for _, g := range f.Comments {
+		for _, cm := range g.List {
+			m := lintDirectiveRx.FindStringSubmatch(cm.Text)
+			if m == nil || !namesCheck(m[2], lintCheck) {
+				continue
+			}
+			pos := fset.Position(cm.Pos())
+			if strings.TrimSpace(m[3]) == "" {
+				malformed = append(malformed, pos)
+				continue
+			}
+			d := &lintDirective{pos: pos}
+			switch m[1] {
+			case "file-ignore":
+				d.end = math.MaxInt
+			case "ignore":
+				d.start = fset.Position(g.Pos()).Offset
+				d.end = fset.Position(ignoredNodeEnd(fset, f, g)).Offset
+			}
+			dirs = append(dirs, d)
+		}
+	}
+	return dirs, malformed
+}
+
+// namesCheck returns whether the comma-separated list of checks includes
+// check.
+func namesCheck(list, check string) bool {
+	for _, c := range strings.Split(list, ",") {
+		if c == check {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredNodeEnd returns the end of the outermost node in f starting on
+// the line after the comment group g, or the end of g if there is none.
+func ignoredNodeEnd(fset *token.FileSet, f *ast.File, g *ast.CommentGroup) token.Pos {
+	end := g.End()
+	line := fset.Position(g.End()).Line + 1
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.File, *ast.CommentGroup, *ast.Comment:
+			return true
+		}
+		if fset.Position(n.Pos()).Line == line && n.End() > end {
+			end = n.End()
+		}
+		return true
+	})
+	return end
+}
+
+// lintIgnored returns the findings in node that are not ignored by linter
+// directives, marking the directives that ignore findings as used.
+func (c *checker) lintIgnored(findings []finding, node ast.Node) []finding {
+	pos := c.fileset.Position(node.Pos())
+	dirs := c.lintDirectives[pos.Filename]
+	if len(dirs) == 0 {
+		return findings
+	}
+	kept := findings[:0]
+	for _, w := range findings {
+		off := pos.Offset + w.span.pos
+		ignored := false
+		for _, d := range dirs {
+			if d.start <= off && off < d.end {
+				d.used = true
+				ignored = true
+			}
+		}
+		if !ignored {
+			kept = append(kept, w)
+		}
+	}
+	return kept
+}
+
+// reportLintDirectives writes malformed linter directives and directives
+// that did not suppress any findings to stdout, and returns whether any
+// were reported.
+func (c *checker) reportLintDirectives() bool {
+	type problem struct {
+		pos token.Position
+		msg string
+	}
+	var problems []problem
+	for _, p := range c.malformedDirectives {
+		problems = append(problems, problem{pos: p, msg: "malformed linter directive; missing the required reason field"})
+	}
+	for _, dirs := range c.lintDirectives {
+		for _, d := range dirs {
+			if d.report && !d.used {
+				problems = append(problems, problem{pos: d.pos, msg: "this linter directive didn't match anything; should it be removed?"})
+			}
+		}
+	}
+	sort.Slice(problems, func(i, j int) bool {
+		pi, pj := problems[i].pos, problems[j].pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+	for _, p := range problems {
+		fmt.Printf("%v:%d:%d: %s\n", rel(p.pos.Filename), p.pos.Line, p.pos.Column, p.msg)
+	}
+	return len(problems) != 0
+}
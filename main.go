@@ -14,8 +14,12 @@ import (
 	"flag"
 	"fmt"
 	"go/ast"
+	"go/token"
 	"os"
+	"path/filepath"
 	"runtime/debug"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -31,41 +35,146 @@ func gospel() (status int) {
 		return status
 	}
 
+	args := os.Args[1:]
+	if len(args) != 0 && args[0] == "words" {
+		return wordsCommand(args[1:])
+	}
+	var logMode, statsMode, initMode, fixMode bool
+	if len(args) != 0 {
+		switch args[0] {
+		case "log":
+			// Check commit messages instead of source.
+			logMode = true
+			args = args[1:]
+		case "stats":
+			// Report the vocabulary instead of misspellings.
+			statsMode = true
+			args = args[1:]
+		case "init":
+			// Bootstrap configuration from the vocabulary.
+			initMode = true
+			args = args[1:]
+		case "fix":
+			// Apply safe fixes instead of reporting.
+			fixMode = true
+			args = args[1:]
+		}
+	}
+
 	// Persisted options.
 	flag.BoolVar(&config.IgnoreIdents, "ignore-idents", config.IgnoreIdents, "ignore words matching identifiers")
-	flag.StringVar(&config.Lang, "lang", config.Lang, "language to use")
+	flag.BoolVar(&config.Harvest.Identifiers, "harvest-identifiers", config.Harvest.Identifiers, "ignore words matching identifier names when ignoring identifiers")
+	flag.BoolVar(&config.Harvest.TagKeys, "harvest-tag-keys", config.Harvest.TagKeys, "ignore words matching struct tag keys when ignoring identifiers")
+	flag.BoolVar(&config.Harvest.TagValues, "harvest-tag-values", config.Harvest.TagValues, "ignore words matching struct tag values when ignoring identifiers")
+	flag.BoolVar(&config.Harvest.Directives, "harvest-directives", config.Harvest.Directives, "ignore words matching directive names when ignoring identifiers")
+	flag.BoolVar(&config.Harvest.PackagePaths, "harvest-package-paths", config.Harvest.PackagePaths, "ignore words matching package path elements when ignoring identifiers")
+	flag.BoolVar(&config.Harvest.ImportedSymbols, "harvest-imported-symbols", config.Harvest.ImportedSymbols, "ignore words matching exported symbols of directly imported packages, and plurals of their type names, when ignoring identifiers")
+	flag.BoolVar(&config.Harvest.NoteAuthors, "harvest-note-authors", config.Harvest.NoteAuthors, "ignore words matching note author user IDs")
+	flag.StringVar(&config.Lang, "lang", config.Lang, "comma-separated list of languages to use; words are correct if any language accepts them")
+	flag.Var(&config.Engine, "engine", "spelling engine to use (hunspell, builtin, pipe)")
+	flag.Var(&config.Tokenizer, "tokenizer", "word tokenizer to use (words, compounds)")
+	flag.BoolVar(&config.CacheDict, "cache-dict", config.CacheDict, "cache the merged base dictionary between runs")
+	flag.BoolVar(&config.ModuleDictionaries, "module-dicts", config.ModuleDictionaries, "use a dictionary for each module with its own lang, .words and affix additions")
+	flag.BoolVar(&config.PackageWords, "package-words", config.PackageWords, "use .words and affix files in package directories for text in and below those directories")
 	flag.BoolVar(&config.Show, "show", config.Show, "print comment or string with misspellings")
+	flag.Var(&config.Format, "format", "output format of findings (text, json)")
 	flag.BoolVar(&config.CheckStrings, "check-strings", config.CheckStrings, "check string literals")
+	flag.BoolVar(&config.CheckFlagUsage, "check-flag-usage", config.CheckFlagUsage, "check flag usage strings even when string literals are not checked")
+	flag.BoolVar(&config.CheckCLIHelp, "check-cli-help", config.CheckCLIHelp, "check cobra and urfave/cli help text even when string literals are not checked")
 	flag.BoolVar(&config.CheckEmbedded, "check-embedded", config.CheckEmbedded, "check embedded data files")
+	flag.BoolVar(&config.CheckChangelogs, "check-changelogs", config.CheckChangelogs, "check changelog and release notes files at module roots")
+	flag.BoolVar(&config.CheckModFiles, "check-mod-files", config.CheckModFiles, "check comments in go.mod and go.work files, including module deprecation notices")
+	flag.BoolVar(&config.CheckModNotices, "check-mod-notices", config.CheckModNotices, "check module deprecation messages and retraction rationales are present and start with a capital when checking go.mod files")
+	flag.BoolVar(&config.CheckTemplates, "check-templates", config.CheckTemplates, "check only literal text in embedded Go templates and templates parsed by ParseFiles, ParseGlob and ParseFS")
+	flag.BoolVar(&config.CheckTemplateSources, "check-template-sources", config.CheckTemplateSources, "check literal text in all Go template files in package directories, including sources of generated files such as README.tmpl.md")
+	flag.BoolVar(&config.CheckCatalogs, "check-catalogs", config.CheckCatalogs, "check only source language messages in .po, .pot and gotext JSON message catalogs")
 	flag.BoolVar(&config.IgnoreUpper, "ignore-upper", config.IgnoreUpper, "ignore all-uppercase words")
 	flag.BoolVar(&config.IgnoreSingle, "ignore-single", config.IgnoreSingle, "ignore single letter words")
 	flag.BoolVar(&config.IgnoreNumbers, "ignore-numbers", config.IgnoreNumbers, "ignore Go syntax number literals")
+	flag.BoolVar(&config.IgnoreMixedAlnum, "ignore-mixed-alnum", config.IgnoreMixedAlnum, "ignore words that mix letters and digits")
+	flag.BoolVar(&config.IgnoreNames, "ignore-names", config.IgnoreNames, "ignore capitalised words in contexts where names of people are expected")
+	flag.BoolVar(&config.SkipHeaders, "skip-headers", config.SkipHeaders, "ignore file header comments such as copyright and license notices")
 	flag.BoolVar(&config.ReadLicenses, "read-licenses", config.ReadLicenses, "ignore words found in license files")
 	flag.BoolVar(&config.GitLog, "read-git-log", config.GitLog, "ignore author names and emails found in `git log` output")
+	flag.BoolVar(&config.ConditionInput, "condition-input", config.ConditionInput, "normalise ligatures and typographic apostrophes in words before checking")
 	flag.BoolVar(&config.MaskFlags, "mask-flags", config.MaskFlags, "ignore words with a leading dash")
+	flag.BoolVar(&config.MaskPlaceholders, "mask-placeholders", config.MaskPlaceholders, "ignore usage placeholders such as <file>, [options] and NAME...")
 	flag.BoolVar(&config.MaskURLs, "mask-urls", config.MaskURLs, "mask URLs in text")
+	flag.BoolVar(&config.MaskSymbolRefs, "mask-symbol-refs", config.MaskSymbolRefs, "mask dotted references to known symbols in comments")
+	flag.BoolVar(&config.MaskCode, "mask-code", config.MaskCode, "ignore comment lines that are commented-out code")
+	flag.BoolVar(&config.MaskTodo, "mask-todo", config.MaskTodo, "ignore comment lines starting with TODO, FIXME, HACK or XXX markers")
+	flag.BoolVar(&config.MaskCitations, "mask-citations", config.MaskCitations, "ignore comment lines that appear to be bibliographic citations")
+	flag.BoolVar(&config.MaskImportPaths, "mask-import-paths", config.MaskImportPaths, "mask import paths in text")
+	flag.BoolVar(&config.MaskPaperRefs, "mask-paper-refs", config.MaskPaperRefs, "mask DOI and arXiv identifier references in text")
+	flag.BoolVar(&config.MaskFences, "mask-fences", config.MaskFences, "ignore the contents of fenced code blocks in comments")
+	flag.BoolVar(&config.MaskDocSyntax, "mask-doc-syntax", config.MaskDocSyntax, "ignore doc comment code blocks, link definitions, inline code spans and Markdown link targets in comments")
+	flag.BoolVar(&config.MaskExampleOutput, "mask-example-output", config.MaskExampleOutput, "ignore example Output: sections in comments")
+	flag.BoolVar(&config.MaskHeaders, "mask-headers", config.MaskHeaders, "ignore punctuation decorating comment section headers")
+	flag.BoolVar(&config.MaskDiagrams, "mask-diagrams", config.MaskDiagrams, "ignore comment lines that appear to be ASCII-art diagrams or table rows")
+	flag.BoolVar(&config.MaskEnvVars, "mask-env-vars", config.MaskEnvVars, "mask $VAR, ${VAR} and %VAR% environment variable references in text")
+	flag.BoolVar(&config.MaskMarkup, "mask-markup", config.MaskMarkup, "mask XML/HTML tags and entities in text")
+	flag.BoolVar(&config.MaskJSON, "mask-json", config.MaskJSON, "check only string values in string literals holding JSON")
 	flag.BoolVar(&config.CheckURLs, "check-urls", config.CheckURLs, "check URLs in text with HEAD request")
+	flag.BoolVar(&config.CheckIssues, "check-issues", config.CheckIssues, "check issue references in text exist using the GitHub API")
+	flag.BoolVar(&config.CheckRFCs, "check-rfcs", config.CheckRFCs, "check RFC references in text against the bundled RFC index")
+	flag.BoolVar(&config.CheckPaperRefs, "check-paper-refs", config.CheckPaperRefs, "check DOI and arXiv identifier references in text exist")
+	flag.BoolVar(&config.CheckSentenceCase, "check-sentence-case", config.CheckSentenceCase, "report doc comment sentences starting with a lowercase word that is not an identifier")
+	flag.BoolVar(&config.CheckConsistency, "check-consistency", config.CheckConsistency, "report words with regional spelling variants that are spelled inconsistently")
+	flag.BoolVar(&config.CheckDocNames, "check-doc-names", config.CheckDocNames, "report exported declaration doc comments that do not start with the declared name")
+	flag.BoolVar(&config.CheckConfusables, "check-confusables", config.CheckConfusables, "report correctly spelled words that are likely to be typos of another word in context as warnings (experimental)")
+	flag.BoolVar(&config.CheckEmbedPatterns, "check-embed-patterns", config.CheckEmbedPatterns, "report //go:embed patterns that match no files or include directories holding large binary files")
+	flag.BoolVar(&config.CheckIgnoredFiles, "check-ignored-files", config.CheckIgnoredFiles, "check Go files excluded by build constraints, such as generators marked with a //go:build ignore constraint")
 	flag.BoolVar(&config.CamelSplit, "camel", config.CamelSplit, "split words on camel case")
 	flag.BoolVar(&config.EntropyFiler.Filter, "entropy-filter", config.EntropyFiler.Filter, "filter strings and embedded files by entropy")
 	flag.IntVar(&config.MinNakedHex, "min-naked-hex", config.MinNakedHex, "length to recognize hex-digit words as number (0 is never ignore)")
+	flag.IntVar(&config.MaxTokenSize, "max-token-size", config.MaxTokenSize, "maximum length in bytes of a word held by the word scanner; longer words are reported as not checked")
+	flag.IntVar(&config.MinWordLen, "min-word-len", config.MinWordLen, "ignore words shorter than this (0 is no limit)")
+	flag.BoolVar(&config.ShowConfidence, "show-confidence", config.ShowConfidence, "show the confidence that each spelling finding is a misspelling")
+	flag.BoolVar(&config.ShowSuppressed, "show-suppressed", config.ShowSuppressed, "list words and lines that were not reported because of heuristics or line patterns, and why")
+	flag.BoolVar(&config.ProfileHeuristics, "profile-heuristics", config.ProfileHeuristics, "report how often each heuristic is used and accepts words, and the time spent in it")
+	flag.BoolVar(&config.DedupeComments, "dedupe-comments", config.DedupeComments, "report findings in comments repeated with identical text once")
+	flag.BoolVar(&config.ModuleRelative, "module-relative", config.ModuleRelative, "report paths relative to the root of the module holding them instead of the working directory")
+	flag.BoolVar(&config.ReportHarvest, "report-harvest", config.ReportHarvest, "report the number of words added to the dictionary from identifiers, struct tags, directives, licenses and other sources")
+	flag.BoolVar(&config.ReportSkipped, "report-skipped", config.ReportSkipped, "report content that was not checked or was only partially checked as info findings")
+	flag.Float64Var(&config.MinConfidence, "min-confidence", config.MinConfidence, "only report spelling findings with at least this confidence (0 is no limit)")
 	flag.IntVar(&config.MaxWordLen, "max-word-len", config.MaxWordLen, "ignore words longer than this (0 is no limit)")
 	flag.Var(&config.MakeSuggestions, "suggest", "make suggestions for misspellings (never, once, each, always)")
+	flag.BoolVar(&config.SuggestNewOnly, "suggest-new-only", config.SuggestNewOnly, "only make suggestions for findings on lines added since the since ref")
+	flag.IntVar(&config.SuggestTimeout, "suggest-timeout", config.SuggestTimeout, "maximum time in milliseconds to spend finding suggestions for a word (0 is no limit)")
+	flag.IntVar(&config.MaxSuggestDistance, "max-suggest-distance", config.MaxSuggestDistance, "maximum edit distance of suggestions from misspelled words (0 is no limit)")
 	flag.IntVar(&config.DiffContext, "diff-context", config.DiffContext, "specify number of lines of change context to include")
+	flag.Var(&config.Severity.ExportedDocs, "severity-exported-docs", "severity of findings in exported doc comments (error, warning)")
+	flag.Var(&config.Severity.Other, "severity-other", "severity of findings outside exported doc comments (error, warning)")
 
 	// Non-persisted config options.
-	flag.StringVar(&config.paths, "dict-paths", config.paths, "directory list containing hunspell dictionaries")
+	flag.StringVar(&config.paths, "dict-paths", config.paths, "directory list containing hunspell dictionaries (a directory followed by :lang merges that dictionary, and DICPATH directories are appended)")
 	flag.StringVar(&config.words, "misspellings", "", "file to write a dictionary of misspellings (.dic format)")
 	flag.BoolVar(&config.update, "update-dict", false, "update misspellings dictionary instead of creating a new one")
 	flag.StringVar(&config.since, "since", config.since, "only consider changes since this ref (requires git)")
+	flag.StringVar(&config.files, "files", "", "comma-separated list of files to check; the packages holding them are loaded")
+	flag.StringVar(&config.decisions, "record-decisions", "", "file to write spelling findings to for triage with the words apply command")
+	flag.BoolVar(&config.commit, "commit", false, "commit files changed by the fix command with git")
+	flag.BoolVar(&config.prompt, "prompt", false, "prompt for the replacement of each spelling finding in the fix command")
+	flag.BoolVar(&config.interactive, "interactive", false, "review each spelling finding in a terminal UI, accepting a suggestion, typing a replacement or adding the word to .words")
+	flag.StringVar(&config.commitMsg, "commit-message", defaultFixMessage, "commit message for the fix command's commit")
 
 	version := flag.Bool("version", false, "update misspellings dictionary instead of creating a new one")
 	writeConf := flag.Bool("write-config", false, "write config file based on flags and existing config to stdout and exit")
 	flag.Bool("config", true, "use config file") // Included for documentation.
 	flag.Usage = func() {
-		fmt.Fprintf(flag.CommandLine.Output(), `usage: %s [options] [packages]
+		fmt.Fprintf(flag.CommandLine.Output(), `usage: %[1]s [options] [packages|files]
+       %[1]s log -since <ref> [options] [packages]
+       %[1]s stats [options] [packages]
+       %[1]s init [options] [packages]
+       %[1]s fix [options] [packages]
+       %[1]s words apply <file>
 
 The gospel program will report misspellings in Go source comments and strings.
 
+Arguments naming .go files, and files listed by the files flag, are checked
+without the rest of the packages holding them, although those packages are
+loaded to provide identifiers and other context.
+
 The position of each comment block or string with misspelled a word will be
 output. If the -show flag is true, the complete comment block or string will
 be printed with misspelled words highlighted.
@@ -81,7 +190,59 @@ requiring the hint to be adjusted.
 If a .gospel.conf file exists in the root of the current module and the config
 flag is true (default) it will be used to populate selected flag defaults:
 show, check-strings, ignore-upper, ignore-single, ignore-numbers, mask-urls,
-camel, min-naked-hex, max-word-len and suggest.
+camel, min-naked-hex, max-word-len, suggest, harvest-identifiers,
+harvest-tag-keys, harvest-tag-values, harvest-directives,
+harvest-package-paths, harvest-imported-symbols, harvest-note-authors, engine,
+tokenizer, cache-dict, module-dicts, package-words, format, check-flag-usage,
+check-cli-help, check-changelogs, check-mod-files, check-mod-notices,
+check-templates, check-template-sources, check-catalogs, ignore-mixed-alnum,
+ignore-names, skip-headers, condition-input, mask-placeholders,
+mask-symbol-refs, mask-code, mask-todo, mask-citations, mask-import-paths,
+mask-paper-refs, mask-fences, mask-doc-syntax, mask-example-output,
+mask-headers, mask-diagrams, mask-env-vars, mask-markup, mask-json,
+check-issues, check-rfcs, check-paper-refs, check-sentence-case,
+check-consistency, check-doc-names, check-confusables, check-embed-patterns,
+check-ignored-files, max-token-size, min-word-len, show-confidence,
+show-suppressed, profile-heuristics, dedupe-comments, module-relative,
+report-harvest, report-skipped, suggest-new-only, suggest-timeout,
+max-suggest-distance, severity-exported-docs and severity-other.
+
+The log command checks the subjects and bodies of git commit messages since
+the ref given by the since flag, or in the range given by the flag, using the
+dictionaries of the modules of the provided packages.
+
+The stats command prints the vocabulary profile of the checked text: the
+number of words, unique words and words used only once, and the most frequent
+terms that are not accepted by the dictionaries or heuristics. This is useful
+for seeding a .words file.
+
+The init command checks the provided packages and writes a starter
+.gospel.conf file, if one does not exist, and a .words file holding the
+terms not accepted by the dictionaries that are used at least twice. If
+stdin is a terminal, each term is confirmed before it is added. It then
+prints an example CI configuration.
+
+The fix command replaces misspelled words with their suggested correction
+when there is exactly one suggestion within two edits of the word and the
+word is in prose rather than adjacent to code markers. Findings that are not
+fixed are reported as TODO items. If the prompt flag is set, the replacement
+for each spelling finding is read from stdin, defaulting to the safe fix if
+there is one. If the commit flag is set, changed lines are formatted with
+gofmt and the files holding them are committed with git. Files that are
+untracked or have uncommitted changes are not fixed when committing.
+
+If the interactive flag is set, each spelling finding is shown with the line
+holding it and its suggestions. In a terminal, the action to take is a key
+press: a suggestion number to accept it, "r" to type a replacement, "a" to
+add the word to the .words file at its module root, "s" or space to skip it,
+or "q" to skip all remaining findings. Otherwise the action is read from
+stdin a line at a time: a suggestion number, a replacement word, "+" to add
+the word, or "-" or empty to skip it, with remaining findings skipped at the
+end of input. Changes are made once all findings have been reviewed.
+
+The words apply command applies the triage decisions in a file written by
+the record-decisions flag. Findings marked "fix" are replaced in the source
+and findings marked "add" are added to the .words file at their module root.
 
 String literals can be filtered on the basis of entropy to exclude unexpectedly
 high or low complexity text from spell checking. This is experimental, and may
@@ -92,7 +253,7 @@ See https://github.com/kortschak/gospel for more complete documentation.
 `, os.Args[0])
 		flag.PrintDefaults()
 	}
-	flag.Parse()
+	flag.CommandLine.Parse(args)
 
 	if *version {
 		info, ok := debug.ReadBuildInfo()
@@ -118,10 +279,24 @@ See https://github.com/kortschak/gospel for more complete documentation.
 		fmt.Fprintln(os.Stderr, "invalid suggest flag value")
 		return invocationError
 	}
-	if strings.Contains(config.since, "..") {
+	if strings.Contains(config.since, "..") && !logMode {
 		fmt.Fprintln(os.Stderr, "cannot use commit range for since argument")
 		return invocationError
 	}
+	if config.interactive && (logMode || statsMode || initMode || fixMode) {
+		fmt.Fprintln(os.Stderr, "interactive flag can only be used when checking packages")
+		return invocationError
+	}
+	var since string
+	if logMode {
+		if config.since == "" {
+			fmt.Fprintln(os.Stderr, "missing since flag for log")
+			return invocationError
+		}
+		// The since flag specifies the commits to check
+		// rather than a filter on source changes.
+		since, config.since = config.since, ""
+	}
 
 	if *writeConf {
 		toml.NewEncoder(os.Stdout).Encode(config)
@@ -138,7 +313,12 @@ See https://github.com/kortschak/gospel for more complete documentation.
 			packages.NeedTypesInfo |
 			packages.NeedModule,
 	}
-	pkgs, err := packages.Load(cfg, flag.Args()...)
+	patterns, files, err := fileQueries(flag.Args(), config.files)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return invocationError
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "load: %v\n", err)
 		return internalError
@@ -146,80 +326,428 @@ See https://github.com/kortschak/gospel for more complete documentation.
 	if packages.PrintErrors(pkgs) != 0 {
 		return internalError
 	}
+	if config.CheckIgnoredFiles {
+		ignored, err := loadIgnoredFiles(cfg, pkgs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "load: %v\n", err)
+			return internalError
+		}
+		pkgs = append(pkgs, ignored...)
+	}
+
+	if config.ModuleRelative {
+		relRoots = moduleRoots(pkgs)
+	}
 
 	d, err := newDictionary(pkgs, config)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return internalError
 	}
+	defer func() {
+		// Failures of external spelling programs
+		// are reported when they are closed.
+		err := d.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status |= internalError
+		}
+	}()
 
 	c, err := newChecker(d, config)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return invocationError
 	}
-	for _, p := range pkgs {
-		c.fileset = p.Fset
-		for _, f := range p.Syntax {
-			if !c.changeFilter.fileIsInChange(f.Pos(), c.fileset) {
-				continue
+	if files != nil {
+		c.changeFilter = c.changeFilter.within(files)
+	}
+	var dicts map[string]*dictionary
+	if config.ModuleDictionaries {
+		dicts, err = newModuleDictionaries(pkgs, d, config)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return internalError
+		}
+	}
+	if config.PackageWords {
+		pkgDicts, err := newPackageDictionaries(pkgs, d, config)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return internalError
+		}
+		if dicts == nil {
+			dicts = pkgDicts
+		}
+		for dir, pd := range pkgDicts {
+			dicts[dir] = pd
+		}
+	}
+	defer func() {
+		for _, md := range dicts {
+			err := md.Close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				status |= internalError
 			}
-			c.noteGenerated(f)
-			if c.CheckStrings {
-				ast.Walk(c, f)
+		}
+	}()
+	c.useModuleDictionaries(dicts)
+	c.ignorer = newIgnorer(pkgs)
+	if statsMode || initMode {
+		c.stats = newWordStats()
+	}
+	c.issueRepo = githubRepo(pkgs)
+	if c.CheckTemplates || c.CheckTemplateSources {
+		c.templates = templateFiles(pkgs)
+	}
+	if c.CheckTemplateSources {
+		err = addTemplateSources(c.templates, pkgs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not find template sources: %v\n", err)
+			return internalError
+		}
+	}
+	if logMode {
+		lines, err := gitCommitLines(since)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not read git log: %v\n", err)
+			return internalError
+		}
+		for _, l := range lines {
+			c.fileset = l
+			c.check(l.text, l)
+		}
+	} else {
+		for _, p := range pkgs {
+			c.fileset = p.Fset
+			var idents map[string]bool
+			if c.CheckSentenceCase {
+				idents = identifiers(p.Syntax)
 			}
-			for _, g := range f.Comments {
-				lastOK := true
-				for i, l := range g.List {
-					ok := c.check(l.Text, l)
-
-					// Provide context for spelling in comments.
-					if !ok {
-						if i != 0 && lastOK {
-							prev := g.List[i-1]
-							c.misspellings = append(c.misspellings, misspelling{
-								text: prev.Text,
-								pos:  c.fileset.Position(prev.Pos()),
-								end:  c.fileset.Position(prev.End()),
-							})
+			for _, f := range p.Syntax {
+				if !c.changeFilter.fileIsInChange(f.Pos(), c.fileset) {
+					continue
+				}
+				ignored, err := c.ignorer.isIgnored(c.fileset.Position(f.Pos()).Filename)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return internalError
+				}
+				if ignored {
+					continue
+				}
+				c.noteGenerated(p, f)
+				if c.CheckSentenceCase {
+					docs := docComments(f)
+					for _, g := range f.Comments {
+						if !docs[g] {
+							continue
+						}
+						for cm, found := range lowercaseStarts(g, idents) {
+							c.style[cm] = append(c.style[cm], found...)
+						}
+					}
+				}
+				if c.exported != nil {
+					for _, g := range exportedDocComments(f) {
+						for _, cm := range g.List {
+							c.exported[cm] = true
+						}
+					}
+				}
+				if c.CheckDocNames {
+					for cm, found := range misnamedDocs(f) {
+						c.style[cm] = append(c.style[cm], found...)
+					}
+				}
+				if c.CheckConfusables {
+					for _, g := range f.Comments {
+						for cm, found := range confusedWords(g) {
+							c.style[cm] = append(c.style[cm], found...)
+						}
+					}
+				}
+				if c.CheckEmbedPatterns {
+					found, err := embedPatternFindings(f, c.fileset.Position(f.Pos()).Filename)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "could not resolve embed patterns: %v\n", err)
+						return internalError
+					}
+					for cm, found := range found {
+						c.style[cm] = append(c.style[cm], found...)
+					}
+				}
+				if c.MaskSymbolRefs {
+					for cm, refs := range symbolRefs(p, f) {
+						c.symbolRefs[cm] = refs
+					}
+				}
+				c.noteLintDirectives(p.Fset, f)
+				kinds := c.kinds(p, c.fileset.Position(f.Pos()).Filename)
+				var help []*ast.BasicLit
+				if c.CheckFlagUsage {
+					help = append(help, flagUsages(p, f)...)
+				}
+				if c.CheckCLIHelp {
+					help = append(help, cliHelpText(p, f)...)
+				}
+				for _, lit := range help {
+					c.helpText[lit] = true
+				}
+				if kinds.strings {
+					ast.Walk(c, f)
+				} else {
+					always := help
+					if c.stringFocus != nil {
+						always = append(always, c.stringFocus.literals(p, f)...)
+					}
+					seen := make(map[*ast.BasicLit]bool)
+					for _, lit := range always {
+						if !seen[lit] {
+							seen[lit] = true
+							c.Visit(lit)
+						}
+					}
+				}
+				if !kinds.comments {
+					continue
+				}
+				for _, g := range f.Comments {
+					if c.SkipHeaders && c.isFileHeader(f, g) {
+						if c.skipped != nil {
+							c.skipped.note(c.fileset.Position(g.Pos()), "file header comment not checked")
 						}
-					} else {
-						if !lastOK {
-							c.misspellings = append(c.misspellings, misspelling{
-								text: l.Text,
-								pos:  c.fileset.Position(l.Pos()),
-								end:  c.fileset.Position(l.End()),
-							})
+						continue
+					}
+					lastOK := true
+					var (
+						inFence  bool
+						block    docBlock
+						inOutput bool
+					)
+					if c.MaskDiagrams {
+						// Table columns may be aligned
+						// across line comments.
+						text := make([]string, len(g.List))
+						for i, l := range g.List {
+							text[i] = l.Text
+						}
+						for i, isRow := range alignedRows(text) {
+							c.tableRows[g.List[i]] = isRow
 						}
 					}
-					lastOK = ok
+					for i, l := range g.List {
+						if c.MaskFences {
+							// Fenced code blocks may span
+							// several line comments.
+							c.inFence[l] = inFence
+							_, inFence = maskFences(l.Text, inFence)
+						}
+						if c.MaskDocSyntax {
+							// As may indented code blocks.
+							c.docBlocks[l] = block
+							_, block = maskDocSyntax(l.Text, block)
+						}
+						if c.MaskExampleOutput {
+							// And example output sections.
+							c.inOutput[l] = inOutput
+							_, inOutput = maskExampleOutput(l.Text, inOutput)
+						}
+						ok := c.check(l.Text, l)
+
+						// Provide context for spelling in comments.
+						if !ok {
+							if i != 0 && lastOK {
+								prev := g.List[i-1]
+								c.checked = append(c.checked, checkedText{
+									text: prev.Text,
+									pos:  c.fileset.Position(prev.Pos()),
+									end:  c.fileset.Position(prev.End()),
+								})
+							}
+						} else {
+							if !lastOK {
+								c.checked = append(c.checked, checkedText{
+									text: l.Text,
+									pos:  c.fileset.Position(l.Pos()),
+									end:  c.fileset.Position(l.End()),
+								})
+							}
+						}
+						lastOK = ok
+					}
 				}
 			}
 		}
-	}
-	if c.CheckEmbedded {
-		var embedded []string
-		for _, pkg := range pkgs {
-			embedded = append(embedded, pkg.EmbedFiles...)
+		isEmbedded := make(map[string]bool)
+		if c.mayCheckEmbedded() {
+			var embedded []string
+			for _, pkg := range pkgs {
+				for _, path := range pkg.EmbedFiles {
+					if c.kinds(pkg, path).embedded {
+						embedded = append(embedded, path)
+						isEmbedded[path] = true
+					}
+				}
+			}
+			const maxLineLen = 120 // TODO(kortschak): Consider making this configurable.
+			for _, path := range embedded {
+				e, err := c.loadEmbedded(path, maxLineLen)
+				if err != nil {
+					fmt.Fprintf(os.Stdout, "could not read embedded file: %v", err)
+					return internalError
+				}
+				if !c.changeFilter.fileIsInChange(e.Pos(), e) {
+					continue
+				}
+				ignored, err := c.ignorer.isIgnored(path)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return internalError
+				}
+				if ignored {
+					continue
+				}
+				asset, err := c.ignorer.isAsset(path)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return internalError
+				}
+				if asset {
+					continue
+				}
+				if e.skipped != "" && c.skipped != nil {
+					c.skipped.note(token.Position{Filename: path, Line: 1, Column: 1}, e.skipped)
+				}
+				c.fileset = e
+				c.check(e.Text(), e)
+			}
 		}
-		const maxLineLen = 120 // TODO(kortschak): Consider making this configurable.
-		for _, path := range embedded {
-			e, err := c.loadEmbedded(path, maxLineLen)
+		if c.CheckChangelogs {
+			paths, err := changelogFiles(pkgs)
 			if err != nil {
-				fmt.Fprintf(os.Stdout, "could not read embedded file: %v", err)
+				fmt.Fprintf(os.Stderr, "could not find changelogs: %v\n", err)
 				return internalError
 			}
-			if !c.changeFilter.fileIsInChange(e.Pos(), e) {
-				continue
+			err = c.checkTextFiles(paths, changelog, nil)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return internalError
 			}
-			c.fileset = e
-			c.check(e.Text(), e)
 		}
+		if c.CheckModFiles {
+			paths, err := modFiles(pkgs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "could not find module files: %v\n", err)
+				return internalError
+			}
+			err = c.checkTextFiles(paths, modFile, func(path string, b []byte) (string, map[int][]finding, error) {
+				if !c.CheckModNotices || filepath.Base(path) != "go.mod" {
+					return string(b), nil, nil
+				}
+				notices, err := modNotices(path, b)
+				if err != nil {
+					return "", nil, fmt.Errorf("could not parse module file: %v", err)
+				}
+				return string(b), notices, nil
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return internalError
+			}
+		}
+		if c.CheckCatalogs {
+			paths, err := catalogFiles(pkgs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "could not find message catalogs: %v\n", err)
+				return internalError
+			}
+			// Embedded catalogs are already checked.
+			paths = slices.DeleteFunc(paths, func(path string) bool { return isEmbedded[path] })
+			err = c.checkTextFiles(paths, messageCatalog, func(path string, b []byte) (string, map[int][]finding, error) {
+				return maskCatalog(path, string(b)), nil, nil
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return internalError
+			}
+		}
+		if c.templates != nil {
+			paths := make([]string, 0, len(c.templates))
+			for path := range c.templates {
+				if !isEmbedded[path] {
+					paths = append(paths, path)
+				}
+			}
+			sort.Strings(paths)
+			err := c.checkTextFiles(paths, goTemplate, func(path string, b []byte) (string, map[int][]finding, error) {
+				return maskTemplate(string(b), c.templates[path]), nil, nil
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return internalError
+			}
+		}
+	}
+	if statsMode {
+		c.stats.report()
+		return success
+	}
+	if initMode {
+		return bootstrap(c.stats, config)
 	}
-	if d.misspellings != 0 {
+	if fixMode {
+		fixed, status := c.applySafeFixes(os.Stdin)
+		if config.commit && len(fixed) != 0 {
+			err = commitFixes(fixed, config.commitMsg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "could not commit fixes: %v\n", err)
+				status |= internalError
+			}
+		}
+		return status
+	}
+	if config.interactive {
+		return c.reviewFindings(os.Stdin, os.Stdout)
+	}
+	switch {
+	case c.failures != 0:
 		status |= spellingError
+	case c.generatedFailures != 0:
+		// Allow pipelines to distinguish findings
+		// that are only in generated code.
+		status |= generatedSpellingError
 	}
 	c.report()
+	if c.reportLintDirectives() {
+		status |= directiveError
+	}
+	if c.suppressed != nil {
+		c.suppressed.report()
+	}
+	if c.skipped != nil {
+		c.skipped.report()
+	}
+	if config.ReportHarvest {
+		d.harvested.report(os.Stderr)
+	}
+	if config.ProfileHeuristics {
+		c.reportHeuristics(os.Stderr)
+	}
+	if c.suggestTimeouts != 0 {
+		fmt.Fprintf(os.Stderr, "suggestions for %d word(s) timed out after %dms\n", c.suggestTimeouts, config.SuggestTimeout)
+	}
+	if config.decisions != "" {
+		err = c.recordDecisions(config.decisions)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status |= internalError
+		}
+	}
+	if c.consistency != nil && c.consistency.report() {
+		status |= spellingError
+	}
 
 	err = d.writeMisspellings()
 	if err != nil {
@@ -229,3 +757,12 @@ See https://github.com/kortschak/gospel for more complete documentation.
 
 	return status
 }
+
+// wordsCommand runs the words command with the provided arguments.
+func wordsCommand(args []string) int {
+	if len(args) != 2 || args[0] != "apply" {
+		fmt.Fprintf(os.Stderr, "usage: %s words apply <file>\n", os.Args[0])
+		return invocationError
+	}
+	return applyDecisions(args[1])
+}
@@ -28,6 +28,11 @@ func TestScripts(t *testing.T) {
 	p := testscript.Params{
 		Dir:           filepath.Join("testdata"),
 		UpdateScripts: *update,
+		Setup: func(env *testscript.Env) error {
+			// Keep dictionary caches in the work directory.
+			env.Setenv("XDG_CACHE_HOME", filepath.Join(env.WorkDir, ".cache"))
+			return nil
+		},
 	}
 	if err := gotooltest.Setup(&p); err != nil {
 		t.Fatal(err)
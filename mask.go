@@ -0,0 +1,384 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maskLines returns text with each line for which mask returns true
+// replaced with spaces. Line endings are retained so that positions
+// within the text are not altered.
+func maskLines(text string, mask func(line string) bool) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, l := range lines {
+		if mask(strings.TrimSuffix(l, "\n")) {
+			lines[i] = blank(l)
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// blank returns s with all bytes other than newlines replaced with
+// spaces.
+func blank(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c != '\n' {
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+// winEnvVars matches candidate Windows style %VAR% environment
+// variable references. Matches are only masked by maskWinEnvVars
+// when they are bounded by white space, quotes or punctuation so
+// that printf verbs such as "%sfoo%d" are not masked.
+var winEnvVars = regexp.MustCompile(`%[A-Za-z_]\w*%`)
+
+// maskWinEnvVars returns text with bounded %VAR% environment variable
+// references replaced with spaces.
+func maskWinEnvVars(text string) string {
+	var b []byte
+	for _, m := range winEnvVars.FindAllStringIndex(text, -1) {
+		if !isVarBoundary(lastRune(text[:m[0]]), true) {
+			continue
+		}
+		r, _ := utf8.DecodeRuneInString(text[m[1]:])
+		if !isVarBoundary(r, false) {
+			continue
+		}
+		if b == nil {
+			b = []byte(text)
+		}
+		for i := m[0]; i < m[1]; i++ {
+			b[i] = ' '
+		}
+	}
+	if b == nil {
+		return text
+	}
+	return string(b)
+}
+
+// lastRune returns the last rune in s, or utf8.RuneError if s is empty.
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+// isVarBoundary returns whether r may bound a %VAR% reference. An
+// empty text boundary is indicated by utf8.RuneError. Opening bounds
+// are white space, quotes and opening brackets, and closing bounds
+// are white space and punctuation other than '%'.
+func isVarBoundary(r rune, open bool) bool {
+	switch {
+	case r == utf8.RuneError, unicode.IsSpace(r):
+		return true
+	case open:
+		return strings.ContainsRune("\"'`([{", r)
+	default:
+		return r != '%' && unicode.IsPunct(r)
+	}
+}
+
+// trimCommentMarkers returns line with any leading or trailing comment
+// markers and surrounding white space removed.
+func trimCommentMarkers(line string) string {
+	line = strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(line, "//"):
+		line = line[2:]
+	case strings.HasPrefix(line, "/*"):
+		line = line[2:]
+	case strings.HasPrefix(line, "*") && !strings.HasPrefix(line, "*/"):
+		// Decorated block comment continuation.
+		line = line[1:]
+	}
+	line = strings.TrimSuffix(line, "*/")
+	return strings.TrimSpace(line)
+}
+
+// codeSignal matches text that has features that are common in Go code
+// but rare in prose. It is used to avoid treating single words or simple
+// labelled phrases, which are valid Go syntax, as code.
+var codeSignal = regexp.MustCompile(`:=|[=!<>]=|\s=\s|<-|\+\+|--|&&|\|\||[{}();\[]|^(?:return|defer|go|func|var|const|type|import|break|continue|goto)\b`)
+
+// isCommentedCode returns whether the comment line appears to be
+// commented-out Go code. The line is considered to be code if it
+// contains syntax typical of code and can be parsed as Go statements
+// or declarations, allowing for unbalanced braces and parentheses that
+// would be closed on other lines.
+func isCommentedCode(line string) bool {
+	line = trimCommentMarkers(line)
+	if line == "" {
+		return false
+	}
+	if strings.Trim(line, "{}()[],;") == "" {
+		// Lines that only close blocks are code if anything is.
+		return true
+	}
+	if !codeSignal.MatchString(line) {
+		return false
+	}
+
+	// Remove closing tokens that belong to previous lines
+	// and provide closing tokens for following lines.
+	var prefix string
+	if strings.HasPrefix(line, "}") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "})"))
+		if strings.HasPrefix(line, "else") {
+			prefix = "if x {} "
+		}
+	}
+	suffix := strings.Repeat(")", max(0, strings.Count(line, "(")-strings.Count(line, ")"))) +
+		strings.Repeat("}", max(0, strings.Count(line, "{")-strings.Count(line, "}")))
+	if strings.HasSuffix(line, "(") || strings.HasSuffix(line, ",") {
+		// Open call or composite literal argument lists.
+		suffix = "x" + suffix
+	}
+	src := prefix + line + suffix
+
+	fset := token.NewFileSet()
+	_, err := parser.ParseFile(fset, "", "package p\nfunc _() {\n"+src+"\n}\n", parser.SkipObjectResolution)
+	if err == nil {
+		return true
+	}
+	_, err = parser.ParseFile(fset, "", "package p\n"+src+"\n", parser.SkipObjectResolution)
+	return err == nil
+}
+
+// isBuildConstraint returns whether the comment line is a //go:build or
+// // +build constraint. The tokens of constraint expressions are build
+// tags and never prose.
+func isBuildConstraint(line string) bool {
+	return constraint.IsGoBuild(line) || constraint.IsPlusBuild(line)
+}
+
+// todoMarker matches the start of comment text that is marked as a
+// note for future work, for example "TODO(name): ..." or "FIXME ...".
+var todoMarker = regexp.MustCompile(`^(?:TODO|FIXME|HACK|XXX)\b`)
+
+// isTodo returns whether the comment line starts with a TODO, FIXME,
+// HACK or XXX marker.
+func isTodo(line string) bool {
+	return todoMarker.MatchString(trimCommentMarkers(line))
+}
+
+// citationSignal matches text that is distinctive of bibliographic
+// citations: "et al.", DOIs, and journal volumes, issues and pages such
+// as "48(3):443-453".
+var citationSignal = regexp.MustCompile(`\bet al\.|\b10\.\d{4,9}/\S+|\b\d+\(\d+\):\d+(?:[-–]\d+)?\b`)
+
+// citationAuthor matches an author name with initials, for example
+// "Needleman, S. B." or "Wunsch C.D.", and citationYear matches a
+// publication year.
+var (
+	citationAuthor = regexp.MustCompile(`\b\p{Lu}[\p{L}'-]+,?\s+(?:\p{Lu}\.\s?){1,3}`)
+	citationYear   = regexp.MustCompile(`\b(?:1[89]|20)\d\d[a-z]?\b`)
+)
+
+// isCitation returns whether the comment line appears to be part of
+// a bibliographic citation. Lines are considered to be citations if
+// they contain "et al.", a DOI or a journal volume, issue and pages, or
+// if they contain both an author name with initials and a year.
+func isCitation(line string) bool {
+	line = trimCommentMarkers(line)
+	if citationSignal.MatchString(line) {
+		return true
+	}
+	return citationAuthor.MatchString(line) && citationYear.MatchString(line)
+}
+
+// codeFence matches comment text that opens or closes a fenced code
+// block, for example "```go" or "~~~".
+var codeFence = regexp.MustCompile("^(?:```[^`]*|~~~.*)$")
+
+// maskFences returns the comment text with the fences and contents of
+// fenced code blocks replaced with spaces, and whether the text ends
+// within a fenced code block. If inFence is true, the text starts within
+// a fenced code block opened by an earlier comment. Line endings are
+// retained so that positions within the text are not altered.
+func maskFences(text string, inFence bool) (string, bool) {
+	lines := strings.SplitAfter(text, "\n")
+	for i, l := range lines {
+		isFence := codeFence.MatchString(trimCommentMarkers(strings.TrimSuffix(l, "\n")))
+		if inFence || isFence {
+			lines[i] = blank(l)
+		}
+		if isFence {
+			inFence = !inFence
+		}
+	}
+	return strings.Join(lines, ""), inFence
+}
+
+// exampleOutput matches comment text that starts the output section of
+// an example, for example "Output:" or "Unordered output: 42".
+var exampleOutput = regexp.MustCompile(`^(?:Unordered output|Output):`)
+
+// maskExampleOutput returns the comment text with example output sections
+// replaced with spaces, and whether the text ends within an output section.
+// An output section starts with a line beginning "Output:" or "Unordered
+// output:" and ends at the next blank line. If inOutput is true, the text
+// starts within an output section begun by an earlier comment. Line endings
+// are retained so that positions within the text are not altered.
+func maskExampleOutput(text string, inOutput bool) (string, bool) {
+	lines := strings.SplitAfter(text, "\n")
+	for i, l := range lines {
+		content := trimCommentMarkers(strings.TrimSuffix(l, "\n"))
+		switch {
+		case content == "":
+			inOutput = false
+		case exampleOutput.MatchString(content):
+			inOutput = true
+		}
+		if inOutput {
+			lines[i] = blank(l)
+		}
+	}
+	return strings.Join(lines, ""), inOutput
+}
+
+// sectionHeader matches comment text that is a section header decorated
+// with runs of punctuation, for example "==== SETUP ====" or
+// "----- helpers -----".
+var sectionHeader = regexp.MustCompile(`^[-=*#~+_/]{3,}\s*[^-=*#~+_/\s]`)
+
+// decoration matches runs of punctuation used to decorate section headers.
+var decoration = regexp.MustCompile(`[-=*#~+_/]{3,}`)
+
+// maskHeaders returns the comment text with the decoration of section
+// header lines replaced with spaces, leaving the header text to be
+// checked. Line endings are retained so that positions within the text
+// are not altered.
+func maskHeaders(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, l := range lines {
+		if sectionHeader.MatchString(trimCommentMarkers(strings.TrimSuffix(l, "\n"))) {
+			lines[i] = decoration.ReplaceAllStringFunc(l, blank)
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// isDiagram returns whether the comment line appears to be part of an
+// ASCII-art or box-drawing diagram, or a row of a table. Lines are
+// considered to be diagrams if they contain box-drawing characters or
+// at least as many punctuation and symbol characters as letters and
+// digits, with a minimum of three. Lines are considered to be table
+// rows if they are delimited by pipe characters. Tables with columns
+// separated by gaps are found by alignedRows.
+func isDiagram(line string) bool {
+	line = trimCommentMarkers(line)
+	if line == "" {
+		return false
+	}
+	var alnum, other int
+	for _, r := range line {
+		switch {
+		case unicode.In(r, boxDrawing):
+			return true
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			alnum++
+		case !unicode.IsSpace(r):
+			other++
+		}
+	}
+	if other >= alnum && other >= 3 {
+		return true
+	}
+	return strings.Count(line, "|") >= 2 && (strings.HasPrefix(line, "|") || strings.HasSuffix(line, "|"))
+}
+
+// boxDrawing is the range table for box-drawing and block element
+// characters.
+var boxDrawing = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x2500, Hi: 0x259f, Stride: 1},
+	},
+}
+
+// alignedRows returns whether each of the comment lines appears to be a
+// row of a table with columns separated by gaps of two or more spaces or
+// a tab. A line is a row if one of its columns starts at the same display
+// column as a column of an adjacent line. Requiring alignment avoids
+// treating prose with two spaces after sentences as a table.
+func alignedRows(lines []string) []bool {
+	starts := make([]map[int]bool, len(lines))
+	for i, l := range lines {
+		starts[i] = columnStarts(l)
+	}
+	rows := make([]bool, len(lines))
+	for i := 1; i < len(lines); i++ {
+		for col := range starts[i] {
+			if starts[i-1][col] {
+				rows[i-1] = true
+				rows[i] = true
+				break
+			}
+		}
+	}
+	return rows
+}
+
+// columnStarts returns the display columns, with tabs expanded to
+// multiples of eight, at which text follows a gap of two or more spaces
+// or a tab within the comment text of line. Indentation before the text
+// is not a gap.
+func columnStarts(line string) map[int]bool {
+	text := trimCommentMarkers(line)
+	if text == "" {
+		return nil
+	}
+	start := strings.Index(line, text)
+	var (
+		starts   map[int]bool
+		col, gap int
+	)
+	for i, r := range line {
+		switch r {
+		case ' ':
+			col++
+			gap++
+			continue
+		case '\t':
+			col += 8 - col%8
+			gap += 2
+			continue
+		case '\r', '\n':
+			return starts
+		}
+		if i > start && gap >= 2 {
+			if starts == nil {
+				starts = make(map[int]bool)
+			}
+			starts[col] = true
+		}
+		gap = 0
+		col++
+	}
+	return starts
+}
+
+// maskAlignedRows returns text with lines that are rows of a table with
+// gap-separated columns replaced with spaces. Line endings are retained
+// so that positions within the text are not altered.
+func maskAlignedRows(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, isRow := range alignedRows(lines) {
+		if isRow {
+			lines[i] = blank(lines[i])
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,206 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+)
+
+// modFiles returns the paths of the go.mod files of the modules of the
+// provided packages and the go.work file of the workspace holding the
+// working directory, if there is one.
+func modFiles(pkgs []*packages.Package) ([]string, error) {
+	seen := make(map[string]bool)
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		if p.Module != nil && p.Module.GoMod != "" {
+			seen[p.Module.GoMod] = true
+		}
+	})
+	work, err := goWorkFile()
+	if err != nil {
+		return nil, err
+	}
+	if work != "" {
+		seen[work] = true
+	}
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// goWorkFile returns the path of the go.work file used by the go command
+// in the working directory, or the empty string if workspaces are not in
+// use.
+func goWorkFile() (string, error) {
+	switch work := os.Getenv("GOWORK"); work {
+	case "off":
+		return "", nil
+	case "":
+	default:
+		return work, nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, "go.work")
+		fi, err := os.Stat(path)
+		if err == nil && fi.Mode().IsRegular() {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// maskModFile returns the go.mod or go.work line with all text other than
+// the text of comments replaced with spaces. This leaves comments on
+// directives, retractions and module deprecation notices to be checked.
+func maskModFile(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '`':
+			quote = c
+		case strings.HasPrefix(line[i:], "//"):
+			return blank(line[:i+2]) + line[i+2:]
+		}
+	}
+	return blank(line)
+}
+
+// modNotices returns the module deprecation notices and retraction
+// rationales in the go.mod file data that break the mod-notice rule,
+// keyed by line number. Spans are relative to the start of the line.
+// A deprecation notice must have a message and a retraction must have a
+// rationale, and neither may start with a lowercase word.
+func modNotices(path string, data []byte) (map[int][]finding, error) {
+	f, err := modfile.ParseLax(path, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	lineStart := []int{0}
+	for i, b := range data {
+		if b == '\n' {
+			lineStart = append(lineStart, i+1)
+		}
+	}
+	found := make(map[int][]finding)
+	report := func(pos modfile.Position, word, note string) {
+		off := pos.Byte - lineStart[pos.Line-1]
+		found[pos.Line] = append(found[pos.Line], finding{
+			category: styleCategory,
+			word:     word,
+			span:     span{pos: off, end: off + len(word)},
+			note:     note,
+			rule:     modNotice,
+		})
+	}
+	checkStart := func(comments []modfile.Comment, prefix string) (ok bool) {
+		for i, c := range comments {
+			if commentText(c) == "" {
+				// A paragraph break ends the notice.
+				return false
+			}
+			from := len("//")
+			if i == 0 {
+				from = strings.Index(c.Token, prefix) + len(prefix)
+			}
+			word, off := firstWord(c.Token, from)
+			if word == "" {
+				continue
+			}
+			if isLowercaseWord(word) {
+				pos := c.Start
+				pos.Byte += off
+				report(pos, strings.TrimRight(word, ".,;:!?"), "a lowercase sentence start")
+			}
+			return true
+		}
+		return false
+	}
+
+	if f.Module != nil {
+		line := f.Module.Syntax
+		comments := append(line.Before[:len(line.Before):len(line.Before)], line.Suffix...)
+		for i, c := range comments {
+			const deprecated = "Deprecated:"
+			if !strings.HasPrefix(commentText(c), deprecated) || (i != 0 && commentText(comments[i-1]) != "") {
+				continue
+			}
+			if !checkStart(comments[i:], deprecated) {
+				pos := c.Start
+				pos.Byte += strings.Index(c.Token, deprecated)
+				report(pos, deprecated, "an empty deprecation message")
+			}
+			break
+		}
+	}
+
+	// Retractions in blocks without their own
+	// comments use the comments of the block.
+	blocks := make(map[*modfile.Line]*modfile.LineBlock)
+	for _, stmt := range f.Syntax.Stmt {
+		if b, ok := stmt.(*modfile.LineBlock); ok {
+			for _, l := range b.Line {
+				blocks[l] = b
+			}
+		}
+	}
+	for _, r := range f.Retract {
+		line := r.Syntax
+		comments := line.Comments
+		if b, ok := blocks[line]; ok && len(comments.Before) == 0 && len(comments.Suffix) == 0 {
+			comments = b.Comments
+		}
+		all := append(comments.Before[:len(comments.Before):len(comments.Before)], comments.Suffix...)
+		var text []modfile.Comment
+		for _, c := range all {
+			if commentText(c) != "" {
+				text = append(text, c)
+			}
+		}
+		if !checkStart(text, "//") {
+			report(line.Start, line.Token[0], "a retraction without a rationale")
+		}
+	}
+	return found, nil
+}
+
+// commentText returns the text of the go.mod comment c without its comment
+// marker and surrounding white space.
+func commentText(c modfile.Comment) string {
+	return strings.TrimSpace(strings.TrimPrefix(c.Token, "//"))
+}
+
+// firstWord returns the first white space delimited word in text at or
+// after the offset from, and the offset of the word.
+func firstWord(text string, from int) (word string, off int) {
+	rest := strings.TrimLeft(text[from:], " \t")
+	off = len(text) - len(rest)
+	if i := strings.IndexAny(rest, " \t"); i >= 0 {
+		rest = rest[:i]
+	}
+	return rest, off
+}
@@ -0,0 +1,226 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/tools/go/packages"
+)
+
+// newModuleDictionaries returns a dictionary for each module root of the
+// provided packages. Each module's dictionary uses the language set in the
+// module's own config file, or the configured language if the module does
+// not set one, and only merges the .words and affix files held in the
+// module's root. Modules using the same language that hold no .words or
+// affix files share a dictionary, so one dictionary is made for each
+// distinct language and word set. The returned dictionaries record
+// misspellings with base.
+func newModuleDictionaries(pkgs []*packages.Package, base *dictionary, cfg config) (map[string]*dictionary, error) {
+	roots := moduleRoots(pkgs)
+	if len(roots) < 2 {
+		return nil, nil
+	}
+	sorted := make([]string, 0, len(roots))
+	for r := range roots {
+		sorted = append(sorted, r)
+	}
+	sort.Strings(sorted)
+
+	dicts := make(map[string]*dictionary)
+	shared := make(map[string]*dictionary)
+	for _, r := range sorted {
+		lang, err := moduleLang(r)
+		if err != nil {
+			return nil, err
+		}
+		modCfg := cfg
+		if lang != "" {
+			modCfg.Lang = lang
+		}
+		key := modCfg.Lang
+		if hasWordSet(r) {
+			key += "\x00" + r
+		}
+		d, ok := shared[key]
+		if !ok {
+			d, err = newRootsDictionary(pkgs, map[string]bool{r: true}, modCfg)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", r, err)
+			}
+			d.misspelled = base.misspelled
+			shared[key] = d
+		}
+		dicts[r] = d
+	}
+	return dicts, nil
+}
+
+// newPackageDictionaries returns a dictionary for each directory below the
+// module roots of the provided packages that holds a .words or affix file
+// and is a package directory or the parent of one. Each dictionary merges
+// the .words and affix files in its directory and in the directories
+// between it and the module root, in addition to those merged for the
+// module: the module root's when module dictionaries are in use, or all the
+// module roots' otherwise. The returned dictionaries record misspellings
+// with base.
+func newPackageDictionaries(pkgs []*packages.Package, base *dictionary, cfg config) (map[string]*dictionary, error) {
+	roots := moduleRoots(pkgs)
+	wordDirs := make(map[string]string) // Word set directory to module root.
+	seen := make(map[string]bool)
+	for _, p := range pkgs {
+		if p.Module == nil || len(p.GoFiles) == 0 {
+			continue
+		}
+		root := filepath.Clean(p.Module.Dir)
+		for dir := filepath.Dir(p.GoFiles[0]); isBelow(dir, root); dir = filepath.Dir(dir) {
+			if seen[dir] {
+				break
+			}
+			seen[dir] = true
+			if hasWordSet(dir) {
+				wordDirs[dir] = root
+			}
+		}
+	}
+	if len(wordDirs) == 0 {
+		return nil, nil
+	}
+	sorted := make([]string, 0, len(wordDirs))
+	for dir := range wordDirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Strings(sorted)
+
+	dicts := make(map[string]*dictionary)
+	for _, dir := range sorted {
+		root := wordDirs[dir]
+		dictRoots := make(map[string]bool)
+		modCfg := cfg
+		if cfg.ModuleDictionaries && len(roots) > 1 {
+			lang, err := moduleLang(root)
+			if err != nil {
+				return nil, err
+			}
+			if lang != "" {
+				modCfg.Lang = lang
+			}
+			dictRoots[root] = true
+		} else {
+			for r := range roots {
+				dictRoots[r] = true
+			}
+		}
+		for d := dir; isBelow(d, root); d = filepath.Dir(d) {
+			if _, ok := wordDirs[d]; ok {
+				dictRoots[d] = true
+			}
+		}
+		d, err := newRootsDictionary(pkgs, dictRoots, modCfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		d.misspelled = base.misspelled
+		dicts[dir] = d
+	}
+	return dicts, nil
+}
+
+// isBelow returns whether dir is within, but is not, the directory root.
+func isBelow(dir, root string) bool {
+	return strings.HasPrefix(dir, root+string(filepath.Separator))
+}
+
+// moduleLang returns the language set in the config file in the module
+// root, or the empty string if there is no config file or it does not set
+// a language.
+func moduleLang(root string) (string, error) {
+	var cfg struct {
+		Lang string `toml:"lang"`
+	}
+	_, err := toml.DecodeFile(filepath.Join(root, configFile), &cfg)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	return cfg.Lang, nil
+}
+
+// hasWordSet returns whether the directory holds a .words or affix file.
+func hasWordSet(dir string) bool {
+	for _, name := range []string{".words", affixFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// dictionaryState is a dictionary and the caches of suggestions made with
+// it.
+type dictionaryState struct {
+	dictionary      *dictionary
+	suggested       map[string][]string
+	dictSuggestions map[string][]string
+}
+
+// useModuleDictionaries configures the checker to use the dictionaries in
+// dicts, keyed by module root or directory, for text in files within each
+// module or directory, with the nearest enclosing directory's dictionary
+// taking precedence. Text outside the modules is checked with the checker's
+// current dictionary.
+func (c *checker) useModuleDictionaries(dicts map[string]*dictionary) {
+	if len(dicts) == 0 {
+		return
+	}
+	c.dictionaries = map[string]*dictionaryState{"": {
+		dictionary:      c.dictionary,
+		suggested:       c.suggested,
+		dictSuggestions: c.dictSuggestions,
+	}}
+	states := make(map[*dictionary]*dictionaryState)
+	for r, d := range dicts {
+		s, ok := states[d]
+		if !ok {
+			s = &dictionaryState{
+				dictionary:      d,
+				dictSuggestions: make(map[string][]string),
+			}
+			if c.suggested != nil {
+				s.suggested = make(map[string][]string)
+			}
+			states[d] = s
+		}
+		c.dictionaries[filepath.Clean(r)] = s
+	}
+}
+
+// useDictionary switches the checker to the dictionary for the nearest
+// module or directory holding the file at path when module or package
+// dictionaries are in use.
+func (c *checker) useDictionary(path string) {
+	if c.dictionaries == nil {
+		return
+	}
+	s := c.dictionaries[""]
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if ms, ok := c.dictionaries[dir]; ok {
+			s = ms
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	c.dictionary = s.dictionary
+	c.suggested = s.suggested
+	c.dictSuggestions = s.dictSuggestions
+}
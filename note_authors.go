@@ -9,8 +9,6 @@ import (
 	"go/ast"
 	"regexp"
 	"strings"
-
-	"github.com/kortschak/hunspell"
 )
 
 // addNoteAuthors is derived from the go/doc readNotes function.
@@ -30,25 +28,25 @@ var (
 // and is followed by the note body (e.g., "// BUG(kortschak): fix this").
 // The note ends at the end of the comment group or at the start of
 // another note in the same comment group, whichever comes first.
-func addNoteAuthors(spelling *hunspell.Spell, comments []*ast.CommentGroup) {
+func addNoteAuthors(spelling speller, comments []*ast.CommentGroup, counts harvestCounts) {
 	for _, g := range comments {
 		i := -1 // comment index of most recent note start, valid if >= 0
 		for j, c := range g.List {
 			if noteCommentRx.MatchString(c.Text) {
 				if i >= 0 {
-					readNote(spelling, g.List[i:j])
+					readNote(spelling, g.List[i:j], counts)
 				}
 				i = j
 			}
 		}
 		if i >= 0 {
-			readNote(spelling, g.List[i:])
+			readNote(spelling, g.List[i:], counts)
 		}
 	}
 }
 
 // readNote collects a single note from a sequence of comments.
-func readNote(spelling *hunspell.Spell, list []*ast.Comment) {
+func readNote(spelling speller, list []*ast.Comment, counts harvestCounts) {
 	text := (&ast.CommentGroup{List: list}).Text()
 	if m := noteMarkerRx.FindStringSubmatchIndex(text); m != nil {
 		if strings.TrimSpace(text[m[1]:]) != "" {
@@ -56,7 +54,7 @@ func readNote(spelling *hunspell.Spell, list []*ast.Comment) {
 			var w words // Use our word scanner to retain parity.
 			sc.Split(w.ScanWords)
 			for sc.Scan() {
-				spelling.Add(sc.Text())
+				counts.add(spelling, noteSource, sc.Text())
 			}
 		}
 	}
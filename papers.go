@@ -0,0 +1,105 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// paperRef is a DOI or arXiv identifier reference to a paper.
+type paperRef struct {
+	span span
+	kind string // kind is "DOI" or "arXiv identifier".
+	id   string
+}
+
+// paperRefs matches DOI references such as "doi:10.1000/xyz123" and arXiv
+// identifier references such as "arXiv:2103.12345" and
+// "arXiv:hep-th/9901001v2". DOIs may not end with trailing punctuation.
+var paperRefs = regexp.MustCompile(`(?i)\bdoi:\s?(10\.\d{4,9}/[^\s"'<>]*[^\s"'<>.,;:)\]])|\barxiv:\s?((?:\d{4}\.\d{4,5}|[a-z-]+(?:\.[a-z]{2})?/\d{7})(?:v\d+)?)\b`)
+
+// findPaperRefs returns the DOI and arXiv identifier references in text.
+func findPaperRefs(text string) []paperRef {
+	var refs []paperRef
+	for _, m := range paperRefs.FindAllStringSubmatchIndex(text, -1) {
+		ref := paperRef{span: span{pos: m[0], end: m[1]}}
+		switch {
+		case m[2] >= 0:
+			ref.kind = "DOI"
+			ref.id = text[m[2]:m[3]]
+		case m[4] >= 0:
+			ref.kind = "arXiv identifier"
+			ref.id = text[m[4]:m[5]]
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// Resolver endpoints for paper references. The DOI handle API reports
+// unregistered DOIs as not found without redirecting to the publisher.
+const (
+	doiAPI   = "https://doi.org/api/handles/%s"
+	arxivAbs = "https://arxiv.org/abs/%s"
+)
+
+// confirmPaperRefs fills and returns dst with a list of DOI and arXiv
+// identifier references that do not exist with the HTTP status or error
+// reasons included.
+func (c *checker) confirmPaperRefs(dst []finding, text string, node ast.Node) []finding {
+	for _, ref := range findPaperRefs(text) {
+		if !c.changeFilter.isInChange(node.Pos()+token.Pos(ref.span.pos), c.fileset) {
+			continue
+		}
+		key := ref.kind + ":" + ref.id
+		note, ok := c.papers[key]
+		if !ok {
+			note = paperStatus(ref)
+			c.papers[key] = note
+		}
+		if note == "" {
+			continue
+		}
+		dst = append(dst, finding{
+			category: referenceCategory,
+			word:     text[ref.span.pos:ref.span.end],
+			span:     ref.span,
+			note:     note,
+		})
+	}
+	return dst
+}
+
+// paperStatus returns a note describing why the referenced paper could
+// not be confirmed to exist, or the empty string if it exists. Papers are
+// only reported as nonexistent if the resolver reports them as not found
+// or gone.
+func paperStatus(ref paperRef) string {
+	endpoint := doiAPI
+	if ref.kind != "DOI" {
+		endpoint = arxivAbs
+	}
+	// DOI suffixes may hold characters that must be escaped,
+	// but the prefix and suffix are separated by a path slash.
+	id := strings.ReplaceAll(url.PathEscape(ref.id), "%2F", "/")
+	resp, err := http.Head(fmt.Sprintf(endpoint, id))
+	if err != nil {
+		return fmt.Sprintf("unreachable %s resolver (%v)", ref.kind, err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return fmt.Sprintf("a nonexistent %s (%v)", ref.kind, resp.Status)
+	}
+	return ""
+}
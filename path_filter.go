@@ -0,0 +1,131 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// pathFilter is a compiled where filter.
+type pathFilter struct {
+	// rules are the path rules for the filter.
+	// Paths are matched with the same semantics
+	// as .gospelignore patterns relative to the
+	// module root.
+	rules []ignoreRule
+
+	whereFilter
+}
+
+// newPathFilters returns the compiled where filters.
+func newPathFilters(filters []whereFilter) ([]pathFilter, error) {
+	pathFilters := make([]pathFilter, 0, len(filters))
+	for _, f := range filters {
+		w, err := newPathFilter(f.Paths)
+		if err != nil {
+			return nil, fmt.Errorf("could not construct where filter: %w", err)
+		}
+		w.whereFilter = f
+		pathFilters = append(pathFilters, w)
+	}
+	return pathFilters, nil
+}
+
+// newPathFilter returns a path filter matching the provided patterns.
+func newPathFilter(patterns []string) (pathFilter, error) {
+	var w pathFilter
+	for _, p := range patterns {
+		r, ok, err := parseIgnoreRule("", p)
+		if err != nil {
+			return pathFilter{}, err
+		}
+		if ok {
+			w.rules = append(w.rules, r)
+		}
+	}
+	return w, nil
+}
+
+// matches returns whether the file at path in the module rooted at root
+// matches the filter's path rules.
+func (w pathFilter) matches(root, path string) bool {
+	var matched bool
+	for _, r := range w.rules {
+		r.base = root
+		if r.matches(path) {
+			matched = !r.negate
+		}
+	}
+	return matched
+}
+
+// textKinds is the set of kinds of text to check in a file.
+type textKinds struct {
+	comments, strings, embedded bool
+}
+
+// kinds returns the kinds of text to check in the file at path, which
+// belongs to pkg. Later where filters take precedence over earlier
+// filters, and the global configuration is used for kinds that are not
+// specified by any matching filter.
+func (c *checker) kinds(pkg *packages.Package, path string) textKinds {
+	k := textKinds{comments: true, strings: c.CheckStrings, embedded: c.CheckEmbedded}
+	if len(c.pathFilters) == 0 {
+		return k
+	}
+	root, path, err := absRoot(pkg, path)
+	if err != nil {
+		return k
+	}
+	for _, w := range c.pathFilters {
+		if !w.matches(root, path) {
+			continue
+		}
+		if w.Comments != nil {
+			k.comments = *w.Comments
+		}
+		if w.Strings != nil {
+			k.strings = *w.Strings
+		}
+		if w.Embedded != nil {
+			k.embedded = *w.Embedded
+		}
+	}
+	return k
+}
+
+// absRoot returns the absolute paths of the root of the module of pkg
+// and of path. If pkg has no module, the current directory is used.
+func absRoot(pkg *packages.Package, path string) (root, abs string, err error) {
+	root = "."
+	if pkg.Module != nil && pkg.Module.Dir != "" {
+		root = pkg.Module.Dir
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return "", "", err
+	}
+	abs, err = filepath.Abs(path)
+	if err != nil {
+		return "", "", err
+	}
+	return root, abs, nil
+}
+
+// mayCheckEmbedded returns whether any embedded file may be checked.
+func (c *checker) mayCheckEmbedded() bool {
+	if c.CheckEmbedded {
+		return true
+	}
+	for _, w := range c.pathFilters {
+		if w.Embedded != nil && *w.Embedded {
+			return true
+		}
+	}
+	return false
+}
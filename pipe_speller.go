@@ -0,0 +1,257 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/execabs"
+)
+
+// pipeSpeller is a speller that uses an external spelling program that
+// implements the ispell pipe protocol, such as aspell, hunspell or nuspell
+// run with the -a option.
+type pipeSpeller struct {
+	// mu protects the pipe since suggestions
+	// may be made concurrently with checks when
+	// they time out.
+	mu    sync.Mutex
+	cmd   *execabs.Cmd
+	stdin io.Closer
+	in    *bufio.Writer
+	out   *bufio.Reader
+
+	// err is the first error communicating
+	// with the program. Once it is set, all
+	// words are treated as correct and the
+	// error is returned by Close.
+	err error
+
+	// closed indicates that the program
+	// has been stopped.
+	closed bool
+}
+
+// newPipeSpeller starts the spelling program given by the command and its
+// arguments, replacing "{lang}" in the arguments with lang, and returns a
+// speller using it. The program provides the language's dictionary. The
+// words in the hunspell format dictionary at dic, if it is not empty, are
+// added to the program's session dictionary without their affix rules.
+func newPipeSpeller(command []string, lang, dic string) (*pipeSpeller, error) {
+	if len(command) == 0 {
+		return nil, errors.New("no engine command for pipe engine")
+	}
+	args := make([]string, len(command)-1)
+	for i, a := range command[1:] {
+		args[i] = strings.ReplaceAll(a, "{lang}", lang)
+	}
+	cmd := execabs.Command(command[0], args...)
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("could not start spelling engine: %v", err)
+	}
+	s := &pipeSpeller{cmd: cmd, stdin: in, in: bufio.NewWriter(in), out: bufio.NewReader(out)}
+
+	// Skip the banner.
+	_, err = s.out.ReadString('\n')
+	if err != nil {
+		s.kill()
+		return nil, fmt.Errorf("could not read spelling engine banner: %v", err)
+	}
+	if dic == "" {
+		return s, nil
+	}
+	err = s.addDictionary(dic)
+	if err != nil {
+		s.kill()
+		return nil, err
+	}
+	return s, nil
+}
+
+// addDictionary adds the words in the hunspell format dictionary at path
+// to the program's session dictionary.
+func (s *pipeSpeller) addDictionary(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for i := 0; sc.Scan(); i++ {
+		if i == 0 {
+			// Skip word count line.
+			continue
+		}
+		w, _, _ := strings.Cut(sc.Text(), "/")
+		if w != "" {
+			s.add(w)
+		}
+	}
+	err = sc.Err()
+	if err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+// Close closes the program's input and waits for it to exit. It returns
+// the first error communicating with the program, or the error from the
+// program's exit if there was none.
+func (s *pipeSpeller) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	cerr := s.stdin.Close()
+	werr := s.cmd.Wait()
+	switch {
+	case s.err != nil:
+		return fmt.Errorf("spelling engine failed: %w", s.err)
+	case cerr != nil:
+		return fmt.Errorf("spelling engine failed: %w", cerr)
+	case werr != nil:
+		return fmt.Errorf("spelling engine failed: %w", werr)
+	}
+	return nil
+}
+
+// kill stops the program without waiting for it to exit cleanly.
+func (s *pipeSpeller) kill() {
+	s.closed = true
+	s.cmd.Process.Kill()
+	s.cmd.Wait()
+}
+
+// IsCorrect returns whether the program accepts word.
+func (s *pipeSpeller) IsCorrect(word string) bool {
+	results := s.query(word)
+	if results == nil {
+		// Words that cannot be checked are
+		// treated as correct.
+		return true
+	}
+	for _, r := range results {
+		switch r[0] {
+		case '*', '+', '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Suggest returns the program's suggestions for word.
+func (s *pipeSpeller) Suggest(word string) []string {
+	var suggestions []string
+	for _, r := range s.query(word) {
+		if r[0] != '&' {
+			continue
+		}
+		// & <word> <count> <offset>: <suggestion>, ...
+		_, list, ok := strings.Cut(r, ": ")
+		if !ok {
+			continue
+		}
+		suggestions = append(suggestions, strings.Split(list, ", ")...)
+	}
+	return suggestions
+}
+
+// Add adds word to the program's session dictionary.
+func (s *pipeSpeller) Add(word string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(word)
+	return s.flush() == nil
+}
+
+// AddWithAffix adds word to the program's session dictionary. The pipe
+// protocol has no way to add affix rules, so example is ignored.
+func (s *pipeSpeller) AddWithAffix(word, example string) bool {
+	return s.Add(word)
+}
+
+// add writes a command to add word to the session dictionary without
+// flushing it.
+func (s *pipeSpeller) add(word string) {
+	if s.err != nil || s.closed || strings.ContainsAny(word, " \t\n") {
+		return
+	}
+	_, err := fmt.Fprintf(s.in, "@%s\n", word)
+	s.setErr(err)
+}
+
+// flush flushes commands written to the program.
+func (s *pipeSpeller) flush() error {
+	if s.err == nil {
+		s.setErr(s.in.Flush())
+	}
+	return s.err
+}
+
+// query returns the result lines of checking word with the program. It
+// returns nil if there was an error communicating with the program.
+func (s *pipeSpeller) query(word string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil || s.closed || word == "" || strings.ContainsAny(word, " \t\n") {
+		return nil
+	}
+	// The leading caret prevents the word from
+	// being interpreted as a command.
+	_, err := fmt.Fprintf(s.in, "^%s\n", word)
+	s.setErr(err)
+	if s.flush() != nil {
+		return nil
+	}
+	var results []string
+	for {
+		line, err := s.out.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			s.setErr(err)
+			return nil
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		results = append(results, line)
+	}
+	if results == nil {
+		// Words with no letters are not checked.
+		results = []string{"*"}
+	}
+	return results
+}
+
+// setErr records err if it is the first error communicating with the
+// program. The error is returned by Close.
+func (s *pipeSpeller) setErr(err error) {
+	if err == nil || s.err != nil {
+		return
+	}
+	s.err = err
+}
@@ -7,39 +7,40 @@ package main
 import (
 	"fmt"
 	"go/token"
+	"os"
 	"sort"
 	"strings"
 )
 
-// misspelling is an identified misspelled word and its position.
-type misspelling struct {
-	text  string
-	where string
-	pos   token.Position
-	end   token.Position
-	words []misspelled
-}
+// checkedText is a comment, string or other text that was checked and
+// the findings in it. Texts without findings are recorded to provide
+// context for findings in adjacent comments.
+type checkedText struct {
+	text     string
+	where    string
+	pos      token.Position
+	end      token.Position
+	findings []finding
 
-// misspelled is a misspelled word and its span.
-type misspelled struct {
-	word    string
-	span    span
-	note    string
-	suggest bool
+	// repeats holds the positions of other
+	// comments with identical text and
+	// findings that are not reported
+	// separately.
+	repeats []token.Position
 }
 
 // adjacent returns whether the receiver is on an adjacent line to
 // prev.
-func (m misspelling) adjacent(prev misspelling) bool {
+func (m checkedText) adjacent(prev checkedText) bool {
 	return m.pos.Filename == prev.pos.Filename &&
 		m.pos.Line-prev.end.Line <= 1
 }
 
 // report writes a report to stdout.
 func (c *checker) report() {
-	sort.Slice(c.misspellings, func(i, j int) bool {
-		mi := c.misspellings[i]
-		mj := c.misspellings[j]
+	sort.Slice(c.checked, func(i, j int) bool {
+		mi := c.checked[i]
+		mj := c.checked[j]
 		switch {
 		case mi.pos.Filename < mj.pos.Filename:
 			return true
@@ -50,65 +51,60 @@ func (c *checker) report() {
 		}
 	})
 
+	checked := c.checked
+	if c.DedupeComments {
+		checked = dedupeComments(checked)
+	}
+	if c.Format == jsonFormat {
+		c.reportJSON(os.Stdout, checked)
+		return
+	}
+
 	var (
-		chunks  [][]misspelling
-		current []misspelling
+		chunks  [][]checkedText
+		current []checkedText
 	)
-	for i, m := range c.misspellings {
-		if i != 0 && !m.adjacent(c.misspellings[i-1]) {
-			chunks = append(chunks, current)
+	for i, m := range checked {
+		if i != 0 && !m.adjacent(checked[i-1]) {
+			chunks = appendChunk(chunks, current)
 			current = nil
 		}
 		current = append(current, m)
 	}
 	if current != nil {
-		chunks = append(chunks, current)
+		chunks = appendChunk(chunks, current)
 	}
 
 	for _, chunk := range chunks {
 		suggested := make(map[string]bool)
 		for _, l := range chunk {
-			for _, w := range l.words {
-				p := l.pos
-				if p.IsValid() {
-					var generated string
-					if c.generated[p.Filename] {
-						generated = " (generated file)"
-					}
-					fmt.Printf("%v:%d:%d: %q is %s in %s%s", rel(p.Filename), p.Line, p.Column+w.span.pos, w.word, w.note, l.where, generated)
-				} else {
-					fmt.Printf("%v@%d: %q is %s in %s", rel(p.Filename), w.span.pos, w.word, w.note, l.where)
+			for _, f := range l.findings {
+				fmt.Printf("%s: %q is %s in %s", f.position(), f.word, f.note, f.where)
+				if f.generated && f.pos.IsValid() {
+					fmt.Print(" (generated file)")
+				}
+				if f.severity == warningLevel {
+					fmt.Print(" (warning)")
+				}
+				if c.ShowConfidence && f.confidence != 0 {
+					fmt.Printf(" (confidence %.2f)", f.confidence)
+				}
+				if f.rule != "" {
+					fmt.Printf(" [%s]", f.rule)
+				}
+				if len(l.repeats) != 0 {
+					fmt.Printf(" (repeated in %s)", repeatList(l.repeats))
 				}
 
-				if w.suggest &&
-					(c.MakeSuggestions == always ||
-						(c.MakeSuggestions == each && !suggested[w.word]) ||
-						(c.MakeSuggestions == once && c.suggested[w.word] == nil)) {
-					suggestions, ok := c.suggested[w.word]
-					if !ok {
-						suggestions = c.dictionary.Suggest(w.word)
-						switch c.MakeSuggestions {
-						case always, each:
-							// Cache suggestions.
-							c.suggested[w.word] = suggestions
-						default:
-							// Mark as suggested.
-							c.suggested[w.word] = empty
-						}
-					}
-					if len(suggestions) != 0 {
-						fmt.Print(" (suggest: ")
-						for i, s := range suggestions {
-							if i != 0 {
-								fmt.Print(", ")
-							}
-							fmt.Printf("%s", c.suggest(s))
-						}
-						fmt.Print(")")
-						if c.MakeSuggestions == each {
-							suggested[w.word] = true
+				if suggestions := c.reportedSuggestions(f, suggested); len(suggestions) != 0 {
+					fmt.Print(" (suggest: ")
+					for i, s := range suggestions {
+						if i != 0 {
+							fmt.Print(", ")
 						}
+						fmt.Printf("%s", c.suggest(s))
 					}
+					fmt.Print(")")
 				}
 				fmt.Println()
 			}
@@ -121,7 +117,7 @@ func (c *checker) report() {
 					continue
 				}
 
-				if len(l.words) == 0 {
+				if len(l.findings) == 0 {
 					if i == 0 || l.text != chunk[i-1].text {
 						fmt.Print(adjustIndents(l.text))
 					}
@@ -132,12 +128,18 @@ func (c *checker) report() {
 					lastPos int
 				)
 				generated := c.generated[l.pos.Filename]
-				for _, w := range l.words {
-					if w.span.pos != lastPos {
-						args = append(args, l.text[lastPos:w.span.pos])
+				for _, f := range l.findings {
+					if f.span.pos < lastPos {
+						// The word is also the subject of an
+						// earlier finding and is already
+						// highlighted.
+						continue
+					}
+					if f.span.pos != lastPos {
+						args = append(args, l.text[lastPos:f.span.pos])
 					}
-					args = append(args, c.warn[generated](l.text[w.span.pos:w.span.pos+len(w.word)]), l.text[w.span.pos+len(w.word):w.span.end])
-					lastPos = w.span.end
+					args = append(args, c.warn[generated](l.text[f.span.pos:f.span.pos+len(f.word)]), l.text[f.span.pos+len(f.word):f.span.end])
+					lastPos = f.span.end
 				}
 				if lastPos != len(l.text) {
 					args = append(args, l.text[lastPos:])
@@ -151,6 +153,107 @@ func (c *checker) report() {
 	}
 }
 
+// reportedSuggestions returns the suggestions to report for the finding
+// f according to the configured suggestion behaviour. The suggested map
+// records the words that have had suggestions reported in the current
+// chunk of findings.
+func (c *checker) reportedSuggestions(f finding, suggested map[string]bool) []string {
+	if f.category != spellingCategory || !c.isAdded(f) {
+		return nil
+	}
+	c.useDictionary(f.pos.Filename)
+	switch {
+	case c.MakeSuggestions == always:
+	case c.MakeSuggestions == each && !suggested[f.word]:
+	case c.MakeSuggestions == once && c.suggested[f.word] == nil:
+	default:
+		return nil
+	}
+	suggestions, ok := c.suggested[f.word]
+	if !ok {
+		suggestions = c.suggestionsFor(f.word)
+		switch c.MakeSuggestions {
+		case always, each:
+			// Cache suggestions.
+			c.suggested[f.word] = suggestions
+		default:
+			// Mark as suggested.
+			c.suggested[f.word] = empty
+		}
+	}
+	if len(suggestions) != 0 && c.MakeSuggestions == each {
+		suggested[f.word] = true
+	}
+	return suggestions
+}
+
+// isAdded returns whether the finding f is on a line added since the
+// since ref when suggestions are restricted to new findings. It returns
+// true when suggestions are not restricted.
+func (c *checker) isAdded(f finding) bool {
+	if c.addedLines == nil {
+		return true
+	}
+	// Findings are positioned relative to the start
+	// of their node, which may span several lines.
+	line := f.pos.Line + strings.Count(f.text[:min(f.span.pos, len(f.text))], "\n")
+	return c.addedLines.hasLine(f.pos.Filename, line)
+}
+
+// appendChunk appends chunk to chunks if it holds any findings. Chunks
+// without findings hold only the context of comments that have been
+// removed as duplicates.
+func appendChunk(chunks [][]checkedText, chunk []checkedText) [][]checkedText {
+	for _, m := range chunk {
+		if len(m.findings) != 0 {
+			return append(chunks, chunk)
+		}
+	}
+	return chunks
+}
+
+// dedupeComments returns the sorted checked texts with comments that have
+// the same text and findings as an earlier comment removed. The positions
+// of removed comments are recorded in the repeats field of the first
+// comment with the text.
+func dedupeComments(checked []checkedText) []checkedText {
+	first := make(map[string]int)
+	var deduped []checkedText
+	for _, m := range checked {
+		if m.where != "comment" || len(m.findings) == 0 {
+			deduped = append(deduped, m)
+			continue
+		}
+		var key strings.Builder
+		key.WriteString(m.text)
+		for _, f := range m.findings {
+			fmt.Fprintf(&key, "\x00%s\x00%d\x00%s\x00%s", f.word, f.span.pos, f.note, f.rule)
+		}
+		i, ok := first[key.String()]
+		if !ok {
+			first[key.String()] = len(deduped)
+			deduped = append(deduped, m)
+			continue
+		}
+		deduped[i].repeats = append(deduped[i].repeats, m.pos)
+	}
+	return deduped
+}
+
+// repeatList returns a description of the positions of repeated
+// comments giving their number and the file and line of each.
+func repeatList(repeats []token.Position) string {
+	pos := make([]string, len(repeats))
+	for i, p := range repeats {
+		pos[i] = fmt.Sprintf("%v:%d", rel(p.Filename), p.Line)
+	}
+	noun := "comments"
+	if len(repeats) == 1 {
+		noun = "comment"
+	}
+	return fmt.Sprintf("%d other %s: %s", len(repeats), noun, strings.Join(pos, ", "))
+}
+
 // join returns the string join of the given args.
 func join(args []interface{}) string {
 	var buf strings.Builder
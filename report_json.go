@@ -0,0 +1,81 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonFinding is the JSON representation of a finding. Line and column
+// fields are omitted when the file has no line information, in which
+// case offsets are relative to the start of the node holding the finding.
+type jsonFinding struct {
+	File      string `json:"file"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	EndColumn int    `json:"end_column,omitempty"`
+	Offset    int    `json:"offset"`
+	EndOffset int    `json:"end_offset"`
+
+	Word        string   `json:"word"`
+	Suggestions []string `json:"suggestions,omitempty"`
+	Kind        string   `json:"kind"`
+	Note        string   `json:"note"`
+
+	Fingerprint string `json:"fingerprint"`
+
+	Category   string  `json:"category"`
+	Rule       string  `json:"rule,omitempty"`
+	Severity   string  `json:"severity"`
+	Generated  bool    `json:"generated,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+	Repeats    int     `json:"repeats,omitempty"`
+}
+
+// reportJSON writes the findings in checked to w as a stream of JSON
+// objects, one per line. Suggestions are included according to the
+// configured suggestion behaviour.
+func (c *checker) reportJSON(w io.Writer, checked []checkedText) {
+	enc := json.NewEncoder(w)
+	suggested := make(map[string]bool)
+	for i, l := range checked {
+		if i != 0 && !l.adjacent(checked[i-1]) {
+			suggested = make(map[string]bool)
+		}
+		for _, f := range l.findings {
+			jf := jsonFinding{
+				File:      rel(f.pos.Filename),
+				Offset:    f.pos.Offset,
+				EndOffset: f.pos.Offset + len(f.word),
+
+				Word:        f.word,
+				Suggestions: c.reportedSuggestions(f, suggested),
+				Kind:        f.where,
+				Note:        f.note,
+
+				Fingerprint: f.fingerprint(),
+
+				Category:   f.category,
+				Rule:       f.rule,
+				Severity:   f.severity.String(),
+				Generated:  f.generated,
+				Confidence: f.confidence,
+				Repeats:    len(l.repeats),
+			}
+			if f.pos.IsValid() {
+				jf.Line = f.pos.Line
+				jf.Column = f.pos.Column
+				jf.EndLine = f.pos.Line
+				jf.EndColumn = f.pos.Column + len(f.word)
+			}
+			if !c.ShowConfidence {
+				jf.Confidence = 0
+			}
+			enc.Encode(jf)
+		}
+	}
+}
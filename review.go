@@ -0,0 +1,168 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// promptEnd is the prompt action returned when there are no more
+// responses to read.
+const promptEnd = "end"
+
+// reviewFindings walks the spelling findings of the checker, writing each
+// to w with the highlighted line holding it and its suggestions, and
+// reading the action to take from r. If r and w are terminals, each
+// finding is shown on a cleared screen and the action is read as a key
+// press, otherwise the action is read as a line of text. Replacements and
+// additions to .words files are applied once all findings have been
+// reviewed. Findings that cannot be reviewed are listed without prompting,
+// after the review when on a terminal. It returns a status that includes
+// spellingError if any findings were skipped.
+func (c *checker) reviewFindings(r io.Reader, w io.Writer) (status int) {
+	sc := bufio.NewScanner(r)
+	prompt := func(f finding, _, _ int) (action, replacement string) {
+		return c.promptFinding(sc, w, f, "", true)
+	}
+	var deferred bytes.Buffer
+	notReviewed := w
+	t, err := newTerminal(r, w)
+	if err == nil {
+		prompt = func(f finding, n, total int) (action, replacement string) {
+			return t.promptFinding(c, f, n, total)
+		}
+		notReviewed = &deferred
+	}
+
+	var total int
+	for _, f := range c.findings() {
+		if isReviewable(f) {
+			total++
+		}
+	}
+	var (
+		decisions []decision
+		n         int
+		end       bool
+	)
+	added := make(map[string]bool)
+	for _, f := range c.findings() {
+		if !isReviewable(f) {
+			fmt.Fprintf(notReviewed, "%s: %q is %s in %s (not reviewed)\n", f.position(), f.word, f.note, f.where)
+			if f.severity == errorLevel {
+				status |= spellingError
+			}
+			continue
+		}
+		n++
+		if added[f.word] {
+			continue
+		}
+		if end {
+			status |= spellingError
+			continue
+		}
+		action, replacement := prompt(f, n, total)
+		switch action {
+		case decideFix, decideAdd:
+			p := f.pos
+			decisions = append(decisions, decision{
+				decision:    action,
+				path:        p.Filename,
+				row:         p.Line,
+				col:         p.Column,
+				word:        f.word,
+				replacement: replacement,
+			})
+			if action == decideAdd {
+				added[f.word] = true
+			}
+		case promptEnd:
+			end = true
+			status |= spellingError
+		default:
+			status |= spellingError
+		}
+	}
+	if t != nil {
+		t.restore()
+		// Clear the screen of the last finding.
+		fmt.Fprint(w, "\x1b[H\x1b[2J")
+		io.Copy(w, &deferred)
+	}
+	return status | applyDecided(decisions)
+}
+
+// isReviewable returns whether the finding f can be reviewed.
+func isReviewable(f finding) bool {
+	return f.category == spellingCategory && f.pos.IsValid() && !f.generated
+}
+
+// promptFinding prompts on w for the action to take for the finding f,
+// reading the response from sc. The response may be the number of one of
+// the listed suggestions, a replacement word, "-" to leave the word
+// unchanged, "+" to add the word to the .words file at its module root if
+// add is true, or empty to accept safe if it is not empty and otherwise to
+// leave the word unchanged. Invalid responses are prompted for again. The
+// returned action is decideFix with the replacement, decideAdd,
+// decideIgnore or, if there are no more responses, promptEnd.
+func (c *checker) promptFinding(sc *bufio.Scanner, w io.Writer, f finding, safe string, add bool) (action, replacement string) {
+	c.useDictionary(f.pos.Filename)
+	suggestions := c.suggestionsFor(f.word)
+	fmt.Fprintf(w, "%s: %q is %s in %s\n", f.position(), f.word, f.note, f.where)
+	fmt.Fprintf(w, "\t%s\n", c.highlightedLine(f))
+	for i, s := range suggestions {
+		fmt.Fprintf(w, "  %d: %s\n", i+1, s)
+	}
+	options := "number, word, - to skip"
+	if add {
+		options += ", + to add to .words"
+	}
+	def := safe
+	if def == "" {
+		def = "skip"
+	}
+	for {
+		fmt.Fprintf(w, "replace %q with [%s; default %s]? ", f.word, options, def)
+		if !sc.Scan() {
+			fmt.Fprintln(w)
+			return promptEnd, ""
+		}
+		resp := strings.TrimSpace(sc.Text())
+		switch {
+		case resp == "" && safe != "":
+			return decideFix, safe
+		case resp == "", resp == "-":
+			return decideIgnore, ""
+		case resp == "+" && add:
+			return decideAdd, ""
+		}
+		i, err := strconv.Atoi(resp)
+		if err != nil && resp != "+" {
+			return decideFix, resp
+		}
+		if err == nil && 1 <= i && i <= len(suggestions) {
+			return decideFix, suggestions[i-1]
+		}
+		fmt.Fprintf(w, "invalid response: %q\n", resp)
+	}
+}
+
+// highlightedLine returns the line of the text holding the finding f with
+// the word highlighted and leading white space removed.
+func (c *checker) highlightedLine(f finding) string {
+	start := strings.LastIndexByte(f.text[:f.span.pos], '\n') + 1
+	end := len(f.text)
+	if i := strings.IndexByte(f.text[f.span.pos:], '\n'); i >= 0 {
+		end = f.span.pos + i
+	}
+	prefix := strings.TrimLeft(f.text[start:f.span.pos], " \t")
+	return prefix + fmt.Sprint(c.warn[f.generated](f.word)) + f.text[f.span.pos+len(f.word):end]
+}
@@ -0,0 +1,50 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:generate go run genrfc.go
+
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// rfcRefs matches RFC references in text, for example "RFC 7231",
+// "RFC-7231" and "rfc7231".
+var rfcRefs = regexp.MustCompile(`(?i)\brfc[ -]?(\d+)\b`)
+
+// isIssuedRFC returns whether n is the number of an issued RFC in the bundled
+// index.
+func isIssuedRFC(n int) bool {
+	if n < 1 || rfcIndexMax < n {
+		return false
+	}
+	i := sort.SearchInts(rfcNotIssued, n)
+	return i == len(rfcNotIssued) || rfcNotIssued[i] != n
+}
+
+// confirmRFCs fills and returns dst with a list of references to RFCs
+// that are not in the bundled index of issued RFCs.
+func (c *checker) confirmRFCs(dst []finding, text string, node ast.Node) []finding {
+	for _, idx := range rfcRefs.FindAllStringSubmatchIndex(text, -1) {
+		if !c.changeFilter.isInChange(node.Pos()+token.Pos(idx[0]), c.fileset) {
+			continue
+		}
+		n, err := strconv.Atoi(text[idx[2]:idx[3]])
+		if err == nil && isIssuedRFC(n) {
+			continue
+		}
+		dst = append(dst, finding{
+			category: referenceCategory,
+			word:     text[idx[0]:idx[1]],
+			span:     span{pos: idx[0], end: idx[1]},
+			note:     "a nonexistent RFC",
+		})
+	}
+	return dst
+}
@@ -0,0 +1,16 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The contents of this file are replaced by running go generate with
+// network access. Until then it holds a conservative index with no
+// known gaps that may report recently issued RFCs as nonexistent.
+
+package main
+
+// rfcIndexMax is the highest RFC number in the bundled index.
+const rfcIndexMax = 9700
+
+// rfcNotIssued is the sorted list of RFC numbers in the bundled
+// index that were not issued.
+var rfcNotIssued = []int{}
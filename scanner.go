@@ -9,18 +9,80 @@ import (
 	"unicode/utf8"
 )
 
+// tokenizer splits text into the words to be checked. The
+// words type is the tokenizer used for all tokenizer kinds,
+// differing only in how words are split.
+type tokenizer interface {
+	// Split is a bufio.SplitFunc that returns
+	// the words in the text.
+	Split(data []byte, atEOF bool) (advance int, token []byte, err error)
+
+	// Current returns the span of the word
+	// most recently returned by Split.
+	Current() span
+
+	// Long returns the spans of words that
+	// were skipped for being too long.
+	Long() []span
+}
+
+// newTokenizer returns a tokenizer of the provided kind that skips words
+// longer than max bytes. If max is zero, words are not limited.
+func newTokenizer(kind tokenizerKind, max int) tokenizer {
+	switch kind {
+	case compoundsTokenizer:
+		return &words{max: max, split: isCompoundSplitter}
+	default:
+		return &words{max: max}
+	}
+}
+
 // words provides a word scanner for bufio.Scanner that can report the
 // position of the last found word in the scanner source.
 type words struct {
 	current span
 
+	// split returns whether the current rune
+	// splits words. If it is nil, isSplitter
+	// is used.
+	split func(prev, curr rune, next []byte, doubleQuoted bool) (width int, ok bool)
+
 	doubleQuoted bool
+
+	// max is the maximum length of a word held
+	// by the scanner. Words that would exceed
+	// max are skipped and their spans recorded
+	// in long. If max is zero, words are not
+	// limited by the scanner.
+	max      int
+	skipping bool
+	long     []span
 }
 
 type span struct {
 	pos, end int
 }
 
+// Split implements tokenizer with ScanBoundedWords.
+func (w *words) Split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	return w.ScanBoundedWords(data, atEOF)
+}
+
+// Current implements tokenizer.
+func (w *words) Current() span { return w.current }
+
+// Long implements tokenizer.
+func (w *words) Long() []span { return w.long }
+
+// isSplitter returns whether the previous, current and next runes indicate
+// the current rune splits words, using the words' split function.
+func (w *words) isSplitter(prev, curr rune, next []byte) (width int, ok bool) {
+	if w.split != nil {
+		return w.split(prev, curr, next, w.doubleQuoted)
+	}
+	return isSplitter(prev, curr, next, w.doubleQuoted)
+}
+
 // ScanWords is derived from the bufio.ScanWords split functions.
 //
 // Copyright 2013 The Go Authors. All rights reserved.
@@ -38,7 +100,7 @@ func (w *words) ScanWords(data []byte, atEOF bool) (advance int, token []byte, e
 	for width := 0; start < len(data); start += width {
 		var r rune
 		r, width = utf8.DecodeRune(data[start:])
-		wid, ok := isSplitter(prev, r, data[start+width:], w.doubleQuoted)
+		wid, ok := w.isSplitter(prev, r, data[start+width:])
 		width += wid
 		if !ok {
 			prev = r
@@ -52,7 +114,7 @@ func (w *words) ScanWords(data []byte, atEOF bool) (advance int, token []byte, e
 	for width, i := 0, start; i < len(data); i += width {
 		var r rune
 		r, width = utf8.DecodeRune(data[i:])
-		wid, ok := isSplitter(prev, r, data[i+width:], w.doubleQuoted)
+		wid, ok := w.isSplitter(prev, r, data[i+width:])
 		width += wid
 		if ok {
 			w.current.end += i + width
@@ -70,6 +132,54 @@ func (w *words) ScanWords(data []byte, atEOF bool) (advance int, token []byte, e
 	return start, nil, nil
 }
 
+// ScanBoundedWords is a split function for a Scanner that returns words
+// as ScanWords does, but skips words that would not fit within w.max bytes
+// rather than failing the scan. The spans of skipped words are recorded
+// in w.long.
+func (w *words) ScanBoundedWords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if w.skipping {
+		return w.skipWord(data, atEOF)
+	}
+	advance, token, err = w.ScanWords(data, atEOF)
+	if w.max == 0 || token != nil || err != nil || atEOF || len(data)-advance < w.max {
+		return advance, token, err
+	}
+	// The word starting at data[advance:] fills the
+	// buffer, so skip it, consuming what we have.
+	w.long = append(w.long, span{pos: w.current.pos})
+	w.skipping = true
+	w.current.end += len(data) - advance
+	return len(data), nil, nil
+}
+
+// skipWord consumes the remainder of a skipped word, ending the skip
+// at the next word split.
+func (w *words) skipWord(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	var prev rune
+	for width, i := 0, 0; i < len(data); i += width {
+		if !atEOF && !utf8.FullRune(data[i:]) {
+			// Request more data to complete the rune.
+			w.current.end += i
+			return i, nil, nil
+		}
+		var r rune
+		r, width = utf8.DecodeRune(data[i:])
+		if _, ok := w.isSplitter(prev, r, data[i+width:]); ok {
+			w.current.end += i
+			w.long[len(w.long)-1].end = w.current.end
+			w.skipping = false
+			return i, nil, nil
+		}
+		prev = r
+	}
+	w.current.end += len(data)
+	if atEOF {
+		w.long[len(w.long)-1].end = w.current.end
+		w.skipping = false
+	}
+	return len(data), nil, nil
+}
+
 // isSplitter returns whether the previous, current rune and next runes indicate
 // the current rune splits words.
 func isSplitter(prev, curr rune, next []byte, doubleQuoted bool) (width int, ok bool) {
@@ -132,6 +242,30 @@ func isSplitter(prev, curr rune, next []byte, doubleQuoted bool) (width int, ok
 	}
 }
 
+// isCompoundSplitter returns whether the previous, current and next runes
+// indicate the current rune splits words as isSplitter does, except that
+// hyphens and word joining punctuation, such as the middle dot in Catalan
+// words like col·lecció, between two letters do not split words.
+func isCompoundSplitter(prev, curr rune, next []byte, doubleQuoted bool) (width int, ok bool) {
+	if isWordJoiner(curr) && unicode.IsLetter(prev) {
+		r, _ := utf8.DecodeRune(next)
+		if unicode.IsLetter(r) {
+			return 0, false
+		}
+	}
+	return isSplitter(prev, curr, next, doubleQuoted)
+}
+
+// isWordJoiner returns whether r is punctuation that may join the parts of
+// a compound word.
+func isWordJoiner(r rune) bool {
+	switch r {
+	case '·', '‧', '׳', '״':
+		return true
+	}
+	return unicode.Is(unicode.Pd, r)
+}
+
 // isWordSplitPunct returns whether the previous, current and next runes
 // indicate that the current rune splits words.
 func isWordSplitPunct(prev, curr rune, next []byte) bool {
@@ -140,9 +274,9 @@ func isWordSplitPunct(prev, curr rune, next []byte) bool {
 
 // isApostrophe returns whether the current rune is an apostrophe. The heuristic
 // used is fairly simple and may not cover all cases correctly, but should handle
-// what we want here.
+// what we want here. Both plain and typographic apostrophes are recognised.
 func isApostrophe(last, curr rune, data []byte) bool {
-	if curr != '\'' {
+	if curr != '\'' && curr != '’' {
 		return false
 	}
 	next, _ := utf8.DecodeRune(data)
@@ -0,0 +1,134 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var scanBoundedWordsTests = []struct {
+	text     string
+	max      int
+	want     []string
+	wantPos  []int
+	wantLong []span
+}{
+	{
+		text:    "one two three",
+		max:     16,
+		want:    []string{"one", "two", "three"},
+		wantPos: []int{0, 4, 8},
+	},
+	{
+		text:     "one " + strings.Repeat("x", 40) + " two",
+		max:      16,
+		want:     []string{"one", "two"},
+		wantPos:  []int{0, 45},
+		wantLong: []span{{pos: 4, end: 44}},
+	},
+	{
+		text:     "one " + strings.Repeat("x", 40),
+		max:      16,
+		want:     []string{"one"},
+		wantPos:  []int{0},
+		wantLong: []span{{pos: 4, end: 44}},
+	},
+	{
+		text:     strings.Repeat("y", 20) + ", then " + strings.Repeat("z", 30) + ".",
+		max:      16,
+		want:     []string{"then"},
+		wantPos:  []int{22},
+		wantLong: []span{{pos: 0, end: 20}, {pos: 27, end: 57}},
+	},
+}
+
+func TestScanBoundedWords(t *testing.T) {
+	for _, test := range scanBoundedWordsTests {
+		sc := bufio.NewScanner(strings.NewReader(test.text))
+		sc.Buffer(make([]byte, 0, 4), test.max)
+		w := words{max: test.max}
+		sc.Split(w.ScanBoundedWords)
+		var (
+			got    []string
+			gotPos []int
+		)
+		for sc.Scan() {
+			got = append(got, sc.Text())
+			gotPos = append(gotPos, w.current.pos)
+		}
+		if err := sc.Err(); err != nil {
+			t.Errorf("unexpected error for %q: %v", test.text, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected words for %q: got:%q want:%q", test.text, got, test.want)
+		}
+		if !reflect.DeepEqual(gotPos, test.wantPos) {
+			t.Errorf("unexpected positions for %q: got:%d want:%d", test.text, gotPos, test.wantPos)
+		}
+		if !reflect.DeepEqual(w.long, test.wantLong) {
+			t.Errorf("unexpected long words for %q: got:%v want:%v", test.text, w.long, test.wantLong)
+		}
+	}
+}
+
+var tokenizerTests = []struct {
+	kind    tokenizerKind
+	text    string
+	want    []string
+	wantPos []int
+}{
+	{
+		kind:    wordsTokenizer,
+		text:    "a col·lecció of state-of-the-art words",
+		want:    []string{"a", "col", "lecció", "of", "state", "of", "the", "art", "words"},
+		wantPos: []int{0, 2, 7, 15, 18, 24, 27, 31, 35},
+	},
+	{
+		kind:    compoundsTokenizer,
+		text:    "a col·lecció of state-of-the-art words",
+		want:    []string{"a", "col·lecció", "of", "state-of-the-art", "words"},
+		wantPos: []int{0, 2, 15, 18, 35},
+	},
+	{
+		kind:    wordsTokenizer,
+		text:    "*emph*, **strong** and _under_ note:",
+		want:    []string{"emph", "strong", "and", "_under_", "note"},
+		wantPos: []int{1, 10, 19, 23, 31},
+	},
+	{
+		kind:    compoundsTokenizer,
+		text:    "-flag and dash- or 1-2",
+		want:    []string{"flag", "and", "dash", "or", "1", "2"},
+		wantPos: []int{1, 6, 10, 16, 19, 21},
+	},
+}
+
+func TestTokenizer(t *testing.T) {
+	for _, test := range tokenizerTests {
+		sc := bufio.NewScanner(strings.NewReader(test.text))
+		w := newTokenizer(test.kind, 0)
+		sc.Split(w.Split)
+		var (
+			got    []string
+			gotPos []int
+		)
+		for sc.Scan() {
+			got = append(got, sc.Text())
+			gotPos = append(gotPos, w.Current().pos)
+		}
+		if err := sc.Err(); err != nil {
+			t.Errorf("unexpected error for %v %q: %v", test.kind, test.text, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected words for %v %q: got:%q want:%q", test.kind, test.text, got, test.want)
+		}
+		if !reflect.DeepEqual(gotPos, test.wantPos) {
+			t.Errorf("unexpected positions for %v %q: got:%d want:%d", test.kind, test.text, gotPos, test.wantPos)
+		}
+	}
+}
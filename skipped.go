@@ -0,0 +1,47 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+)
+
+// skipped records content that was not checked or was only partially
+// checked.
+type skipped struct {
+	list []skip
+}
+
+// skip is a description of skipped content and its position.
+type skip struct {
+	pos token.Position
+	msg string
+}
+
+// note records the skipped content at pos described by msg.
+func (s *skipped) note(pos token.Position, msg string) {
+	s.list = append(s.list, skip{pos: pos, msg: msg})
+}
+
+// report writes a report of skipped content to stdout as info findings.
+func (s *skipped) report() {
+	sort.SliceStable(s.list, func(i, j int) bool {
+		pi, pj := s.list[i].pos, s.list[j].pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+	for _, sk := range s.list {
+		p := sk.pos
+		if p.IsValid() {
+			fmt.Printf("%v:%d:%d: info: %s\n", rel(p.Filename), p.Line, p.Column, sk.msg)
+		} else {
+			fmt.Printf("%v@%d: info: %s\n", rel(p.Filename), p.Offset, sk.msg)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// speller is a spelling engine holding a dictionary.
+type speller interface {
+	// IsCorrect returns whether word is correctly
+	// spelled according to the dictionary.
+	IsCorrect(word string) bool
+
+	// Suggest returns suggested corrections for word.
+	Suggest(word string) []string
+
+	// Add adds word to the dictionary, returning
+	// whether it was added.
+	Add(word string) bool
+
+	// AddWithAffix adds word to the dictionary with
+	// the affix rules of example, returning whether
+	// it was added.
+	AddWithAffix(word, example string) bool
+}
+
+// newSpeller returns a speller for lang using the configured engine, with
+// the affix rules in the file aff and the words in the file dic.
+func newSpeller(cfg config, lang, aff, dic string) (speller, error) {
+	switch cfg.Engine {
+	case hunspellEngine:
+		return newHunspell(aff, dic)
+	case builtinEngine:
+		return newBuiltinSpeller(aff, dic)
+	case pipeEngine:
+		return newPipeSpeller(cfg.EngineCommand, lang, dic)
+	default:
+		return nil, fmt.Errorf("unknown spelling engine: %v", cfg.Engine)
+	}
+}
+
+// dictFiles returns the paths of the hunspell affix and dictionary files
+// for lang in the directory dir.
+func dictFiles(dir, lang string) (aff, dic string, err error) {
+	if lang == "" {
+		return "", "", errors.New("missing lang")
+	}
+	return filepath.Join(dir, lang+".aff"), filepath.Join(dir, lang+".dic"), nil
+}
@@ -0,0 +1,81 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxStatsTerms is the number of out-of-dictionary terms listed by the
+// stats command.
+const maxStatsTerms = 20
+
+// wordStats records the vocabulary of checked text.
+type wordStats struct {
+	// words is the total number of words seen.
+	words int
+
+	// counts is the number of times each
+	// word has been seen, keyed by the
+	// lowercase form of the word.
+	counts map[string]int
+
+	// unknown is the number of times each
+	// word that was not accepted by the
+	// dictionary or heuristics has been
+	// seen.
+	unknown map[string]int
+}
+
+// newWordStats returns a new wordStats.
+func newWordStats() *wordStats {
+	return &wordStats{
+		counts:  make(map[string]int),
+		unknown: make(map[string]int),
+	}
+}
+
+// note records the word and whether it was accepted.
+func (s *wordStats) note(word string, ok bool) {
+	s.words++
+	s.counts[strings.ToLower(word)]++
+	if !ok {
+		s.unknown[word]++
+	}
+}
+
+// report writes a report of the vocabulary profile to stdout.
+func (s *wordStats) report() {
+	var hapax int
+	for _, n := range s.counts {
+		if n == 1 {
+			hapax++
+		}
+	}
+	fmt.Printf("words: %d\n", s.words)
+	fmt.Printf("unique words: %d\n", len(s.counts))
+	fmt.Printf("hapax legomena: %d\n", hapax)
+	fmt.Printf("out-of-dictionary terms: %d\n", len(s.unknown))
+
+	terms := make([]string, 0, len(s.unknown))
+	for t := range s.unknown {
+		terms = append(terms, t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		ni, nj := s.unknown[terms[i]], s.unknown[terms[j]]
+		if ni != nj {
+			return ni > nj
+		}
+		return terms[i] < terms[j]
+	})
+	if len(terms) > maxStatsTerms {
+		terms = terms[:maxStatsTerms]
+	}
+	for _, t := range terms {
+		fmt.Printf("\t%d\t%s\n", s.unknown[t], t)
+	}
+}
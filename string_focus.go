@@ -0,0 +1,118 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strconv"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// stringFocus is the set of struct fields and function arguments whose
+// string literals are always checked.
+type stringFocus struct {
+	// fields is the set of struct field names.
+	fields map[string]bool
+
+	// args is the set of argument indexes
+	// keyed by the full name of the function
+	// or method as given by types.Func.
+	args map[string]map[int]bool
+}
+
+// focusArg matches function argument specifications in the form
+// "net/http.Error(1)" or "(*example.com/pkg.T).Method(0)".
+var focusArg = regexp.MustCompile(`^(.+)\((\d+)\)$`)
+
+// newStringFocus returns a stringFocus for the provided specifications.
+// Each specification is either a struct field name, or the full name of
+// a function or method followed by the zero-based index of an argument
+// in parentheses.
+func newStringFocus(specs []string) (*stringFocus, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	f := &stringFocus{
+		fields: make(map[string]bool),
+		args:   make(map[string]map[int]bool),
+	}
+	for _, s := range specs {
+		m := focusArg.FindStringSubmatch(s)
+		if m == nil {
+			if !token.IsIdentifier(s) {
+				return nil, fmt.Errorf("invalid field name: %q", s)
+			}
+			f.fields[s] = true
+			continue
+		}
+		i, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument index: %q", s)
+		}
+		if f.args[m[1]] == nil {
+			f.args[m[1]] = make(map[int]bool)
+		}
+		f.args[m[1]][i] = true
+	}
+	return f, nil
+}
+
+// literals returns the string literals in f that are held by focus
+// struct fields in keyed composite literals or passed as focus function
+// arguments.
+func (s *stringFocus) literals(p *packages.Package, f *ast.File) []*ast.BasicLit {
+	var lits []*ast.BasicLit
+	collect := func(n ast.Node) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				lits = append(lits, lit)
+			}
+			return true
+		})
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			if _, ok := p.TypesInfo.TypeOf(n).Underlying().(*types.Struct); !ok {
+				return true
+			}
+			for _, e := range n.Elts {
+				kv, ok := e.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if key, ok := kv.Key.(*ast.Ident); ok && s.fields[key.Name] {
+					collect(kv.Value)
+				}
+			}
+		case *ast.CallExpr:
+			var id *ast.Ident
+			switch fun := n.Fun.(type) {
+			case *ast.SelectorExpr:
+				id = fun.Sel
+			case *ast.Ident:
+				id = fun
+			default:
+				return true
+			}
+			fn, ok := p.TypesInfo.Uses[id].(*types.Func)
+			if !ok {
+				return true
+			}
+			for i := range s.args[fn.FullName()] {
+				if i < len(n.Args) {
+					collect(n.Args[i])
+				}
+			}
+		}
+		return true
+	})
+	return lits
+}
@@ -0,0 +1,354 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Style rule IDs.
+const (
+	// sentenceCase is the rule for doc comment sentences
+	// starting with a lowercase word.
+	sentenceCase = "sentence-case"
+
+	// docName is the rule for doc comments that do not
+	// start with the name of the documented declaration.
+	docName = "doc-name"
+
+	// embedPattern is the rule for //go:embed patterns
+	// that match no files or that include directories
+	// holding large binary files.
+	embedPattern = "embed-pattern"
+
+	// modNotice is the rule for module deprecation
+	// messages and retraction rationales that are
+	// missing or start with a lowercase word.
+	modNotice = "mod-notice"
+)
+
+// docComments returns the set of doc comment groups in f.
+func docComments(f *ast.File) map[*ast.CommentGroup]bool {
+	docs := make(map[*ast.CommentGroup]bool)
+	add := func(g *ast.CommentGroup) {
+		if g != nil {
+			docs[g] = true
+		}
+	}
+	add(f.Doc)
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			add(n.Doc)
+		case *ast.GenDecl:
+			add(n.Doc)
+		case *ast.TypeSpec:
+			add(n.Doc)
+		case *ast.ValueSpec:
+			add(n.Doc)
+		case *ast.Field:
+			add(n.Doc)
+		}
+		return true
+	})
+	return docs
+}
+
+// identifiers returns the set of identifier names used in files.
+func identifiers(files []*ast.File) map[string]bool {
+	idents := make(map[string]bool)
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				idents[id.Name] = true
+			}
+			return true
+		})
+	}
+	return idents
+}
+
+var (
+	// listMarker matches doc comment list item markers.
+	listMarker = regexp.MustCompile(`^(?:[-*+•]|\d+[.)])\s`)
+
+	// abbreviations is the set of abbreviations that end with
+	// a period but do not end a sentence.
+	abbreviations = map[string]bool{
+		"e.g.": true, "i.e.": true, "etc.": true, "cf.": true,
+		"vs.": true, "approx.": true, "esp.": true, "viz.": true,
+	}
+)
+
+// lowercaseStarts returns the sentence-starting words in the doc comment
+// group g that are lowercase and are not identifiers in idents. Findings
+// are keyed by the comment holding them, and their spans are relative to
+// the comment's text. Headings, code blocks and directives are skipped.
+func lowercaseStarts(g *ast.CommentGroup, idents map[string]bool) map[*ast.Comment][]finding {
+	var found map[*ast.Comment][]finding
+	start := true
+	for _, cm := range g.List {
+		if strings.HasPrefix(cm.Text, "//go:") || strings.HasPrefix(cm.Text, "//line ") {
+			continue
+		}
+		var off int
+		for _, line := range strings.SplitAfter(cm.Text, "\n") {
+			lineOff := off
+			off += len(line)
+
+			content, pos := docContent(line)
+			item := listMarker.FindString(strings.TrimLeft(content, " \t"))
+			switch {
+			case item != "":
+				// List items are often fragments, so
+				// don't require a capitalised start.
+				start = false
+				i := strings.Index(content, item) + len(item)
+				content = content[i:]
+				pos += i
+			case strings.TrimSpace(content) == "", strings.HasPrefix(content, "#"),
+				strings.HasPrefix(content, "\t"), strings.HasPrefix(content, " "):
+				// Paragraph breaks, headings and code
+				// blocks end sentences.
+				start = true
+				continue
+			}
+			for _, idx := range tokens.FindAllStringIndex(content, -1) {
+				word := content[idx[0]:idx[1]]
+				if start && isLowercaseWord(word) && !idents[strings.TrimRight(word, ".,;:!?")] {
+					if found == nil {
+						found = make(map[*ast.Comment][]finding)
+					}
+					w := strings.TrimRight(word, ".,;:!?")
+					p := lineOff + pos + idx[0]
+					found[cm] = append(found[cm], finding{
+						category: styleCategory,
+						word:     w,
+						span:     span{pos: p, end: p + len(w)},
+						note:     "a lowercase sentence start",
+						rule:     sentenceCase,
+					})
+				}
+				start = endsSentence(word)
+			}
+		}
+	}
+	return found
+}
+
+// tokens matches white space delimited tokens.
+var tokens = regexp.MustCompile(`\S+`)
+
+// docContent returns the text of a comment line without its comment
+// markers and the offset of the text in the line.
+func docContent(line string) (string, int) {
+	line = strings.TrimSuffix(line, "\n")
+	content := strings.TrimLeft(line, " \t")
+	pos := len(line) - len(content)
+	switch {
+	case strings.HasPrefix(content, "//"), strings.HasPrefix(content, "/*"):
+		content = content[2:]
+		pos += 2
+	}
+	content = strings.TrimSuffix(content, "*/")
+	if strings.HasPrefix(content, " ") {
+		// Remove the conventional single space.
+		content = content[1:]
+		pos++
+	}
+	return content, pos
+}
+
+// isLowercaseWord returns whether word, ignoring trailing punctuation, is
+// made only of letters and starts with a lowercase letter.
+func isLowercaseWord(word string) bool {
+	word = strings.TrimRight(word, ".,;:!?")
+	r, _ := utf8.DecodeRuneInString(word)
+	if !unicode.IsLower(r) {
+		return false
+	}
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// endsSentence returns whether word ends a sentence.
+func endsSentence(word string) bool {
+	if abbreviations[strings.ToLower(word)] {
+		return false
+	}
+	word = strings.TrimRight(word, `"')]`+"`")
+	return strings.HasSuffix(word, ".") || strings.HasSuffix(word, "!") || strings.HasSuffix(word, "?")
+}
+
+// articles is the set of words that may precede a declaration's name at
+// the start of its doc comment.
+var articles = map[string]bool{"A": true, "An": true, "The": true}
+
+// misnamedDocs returns the doc comments of exported declarations in f
+// that do not begin with the name of the declaration, optionally preceded
+// by an article. Findings are keyed by the first comment of the group,
+// and hold the first word of the comment. Deprecated notices and
+// declaration groups with a single doc comment for several names are
+// not reported.
+func misnamedDocs(f *ast.File) map[*ast.Comment][]finding {
+	found := make(map[*ast.Comment][]finding)
+	check := func(doc *ast.CommentGroup, names ...*ast.Ident) {
+		if doc == nil || len(names) != 1 || !names[0].IsExported() {
+			return
+		}
+		cm := doc.List[0]
+		content, pos := docContent(strings.SplitN(cm.Text, "\n", 2)[0])
+		if strings.TrimSpace(content) == "" && strings.HasPrefix(cm.Text, "/*") {
+			// Use the first line of text in block comments.
+			lines := strings.SplitAfter(cm.Text, "\n")
+			off := len(lines[0])
+			for _, l := range lines[1:] {
+				if strings.TrimSpace(l) != "" {
+					content, pos = docContent(l)
+					pos += off
+					break
+				}
+				off += len(l)
+			}
+		}
+		idx := tokens.FindAllStringIndex(content, 2)
+		if len(idx) == 0 {
+			return
+		}
+		first := content[idx[0][0]:idx[0][1]]
+		if strings.HasPrefix(first, "Deprecated:") {
+			return
+		}
+		if strings.TrimRight(first, ".,;:") == names[0].Name {
+			return
+		}
+		if articles[first] && len(idx) > 1 && strings.TrimRight(content[idx[1][0]:idx[1][1]], ".,;:") == names[0].Name {
+			return
+		}
+		p := pos + idx[0][0]
+		found[cm] = append(found[cm], finding{
+			category: styleCategory,
+			word:     first,
+			span:     span{pos: p, end: p + len(first)},
+			note:     fmt.Sprintf("not the declared name (%s)", names[0].Name),
+			rule:     docName,
+		})
+	}
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil && !exportedReceiver(d.Recv) {
+				continue
+			}
+			check(d.Doc, d.Name)
+		case *ast.GenDecl:
+			if d.Lparen.IsValid() {
+				for _, s := range d.Specs {
+					switch s := s.(type) {
+					case *ast.TypeSpec:
+						check(s.Doc, s.Name)
+					case *ast.ValueSpec:
+						check(s.Doc, s.Names...)
+					}
+				}
+				continue
+			}
+			if len(d.Specs) != 1 {
+				continue
+			}
+			switch s := d.Specs[0].(type) {
+			case *ast.TypeSpec:
+				check(d.Doc, s.Name)
+			case *ast.ValueSpec:
+				check(d.Doc, s.Names...)
+			}
+		}
+	}
+	return found
+}
+
+// exportedReceiver returns whether the receiver's base type is exported.
+func exportedReceiver(recv *ast.FieldList) bool {
+	if len(recv.List) == 0 {
+		return false
+	}
+	typ := recv.List[0].Type
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		case *ast.ParenExpr:
+			typ = t.X
+		case *ast.Ident:
+			return t.IsExported()
+		default:
+			return false
+		}
+	}
+}
+
+// exportedDocComments returns the package doc comment and the doc comments
+// of exported declarations, including exported fields and methods of
+// exported types, in f.
+func exportedDocComments(f *ast.File) []*ast.CommentGroup {
+	var docs []*ast.CommentGroup
+	add := func(g *ast.CommentGroup) {
+		if g != nil {
+			docs = append(docs, g)
+		}
+	}
+	add(f.Doc)
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if d.Name.IsExported() && (d.Recv == nil || exportedReceiver(d.Recv)) {
+				add(d.Doc)
+			}
+		case *ast.GenDecl:
+			var exported bool
+			for _, s := range d.Specs {
+				switch s := s.(type) {
+				case *ast.TypeSpec:
+					if !s.Name.IsExported() {
+						continue
+					}
+					exported = true
+					add(s.Doc)
+					ast.Inspect(s.Type, func(n ast.Node) bool {
+						if fld, ok := n.(*ast.Field); ok && len(fld.Names) != 0 && fld.Names[0].IsExported() {
+							add(fld.Doc)
+						}
+						return true
+					})
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if n.IsExported() {
+							exported = true
+							add(s.Doc)
+							break
+						}
+					}
+				}
+			}
+			if exported {
+				add(d.Doc)
+			}
+		}
+	}
+	return docs
+}
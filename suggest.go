@@ -0,0 +1,151 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// dictionarySuggest returns the dictionary's suggestions for word. Results
+// are cached so that the dictionary is asked at most once for each word,
+// however many times the word is found or suggestions are needed for it.
+// Suggestions are only sought once checking is complete, for words that
+// are reported, except when confidence scores are needed since the score
+// of a misspelling depends on its suggestions. If SuggestTimeout is
+// positive, no suggestions are returned for words whose suggestions take
+// longer than SuggestTimeout milliseconds to find.
+func (c *checker) dictionarySuggest(word string) []string {
+	if s, ok := c.dictSuggestions[word]; ok {
+		return slices.Clone(s)
+	}
+	var suggestions []string
+	if c.SuggestTimeout <= 0 {
+		suggestions = c.dictionary.Suggest(word)
+	} else {
+		var ok bool
+		suggestions, ok = c.dictionary.suggestWithin(word, time.Duration(c.SuggestTimeout)*time.Millisecond)
+		if !ok {
+			c.suggestTimeouts++
+		}
+	}
+	c.dictSuggestions[word] = suggestions
+	return slices.Clone(suggestions)
+}
+
+// suggestionsFor returns spelling suggestions for word. Splits of word
+// into two correctly spelled words are suggested ahead of the dictionary's
+// suggestions, since hunspell rarely suggests splitting words that have
+// been joined. Suggestions that differ from word only by keys adjacent
+// on a QWERTY keyboard are placed first. Suggestions further than
+// MaxSuggestDistance edits from word are omitted when MaxSuggestDistance
+// is positive.
+func (c *checker) suggestionsFor(word string) []string {
+	suggestions := c.dictionarySuggest(word)
+	splits := c.splits(word)
+	for i := len(splits) - 1; i >= 0; i-- {
+		if !slices.Contains(suggestions, splits[i]) {
+			suggestions = slices.Insert(suggestions, 0, splits[i])
+		}
+	}
+	slices.SortStableFunc(suggestions, func(a, b string) int {
+		switch ta, tb := isKeyboardTypo(word, a), isKeyboardTypo(word, b); {
+		case ta && !tb:
+			return -1
+		case !ta && tb:
+			return 1
+		}
+		return 0
+	})
+	if c.MaxSuggestDistance > 0 {
+		word = strings.ToLower(word)
+		suggestions = slices.DeleteFunc(suggestions, func(s string) bool {
+			return editDistance(word, strings.ToLower(s)) > c.MaxSuggestDistance
+		})
+	}
+	return suggestions
+}
+
+// minSplitLen is the shortest part of a split word that is suggested.
+const minSplitLen = 2
+
+// splits returns the ways word can be split into two correctly spelled
+// words, joined by a space. Splits with a longer first word are returned
+// first.
+func (c *checker) splits(word string) []string {
+	var splits []string
+	for i := len(word) - minSplitLen; i >= minSplitLen; i-- {
+		left, right := word[:i], word[i:]
+		if !c.dictionary.IsCorrect(left) || !c.dictionary.IsCorrect(right) {
+			continue
+		}
+		splits = append(splits, left+" "+right)
+	}
+	return splits
+}
+
+// qwerty is the letter layout of a QWERTY keyboard and the horizontal
+// offset of each row from the top row, in units of key width.
+var qwerty = []struct {
+	keys   string
+	offset float64
+}{
+	{keys: "qwertyuiop", offset: 0},
+	{keys: "asdfghjkl", offset: 0.25},
+	{keys: "zxcvbnm", offset: 0.75},
+}
+
+// isKeyboardTypo returns whether s differs from word only by the
+// substitution of letters for letters on adjacent QWERTY keys, ignoring
+// case.
+func isKeyboardTypo(word, s string) bool {
+	rw, rs := []rune(strings.ToLower(word)), []rune(strings.ToLower(s))
+	if len(rw) != len(rs) {
+		return false
+	}
+	var typo bool
+	for i, r := range rw {
+		if r == rs[i] {
+			continue
+		}
+		if !isAdjacentKey(r, rs[i]) {
+			return false
+		}
+		typo = true
+	}
+	return typo
+}
+
+// isAdjacentKey returns whether the lowercase letters a and b are on
+// adjacent keys of a QWERTY keyboard.
+func isAdjacentKey(a, b rune) bool {
+	rowA, xA, okA := keyPosition(a)
+	rowB, xB, okB := keyPosition(b)
+	if !okA || !okB {
+		return false
+	}
+	dx := math.Abs(xA - xB)
+	switch rowA - rowB {
+	case 0:
+		return dx == 1
+	case -1, 1:
+		return dx < 1
+	}
+	return false
+}
+
+// keyPosition returns the row and horizontal position of the key for
+// the lowercase letter r on a QWERTY keyboard.
+func keyPosition(r rune) (row int, x float64, ok bool) {
+	for row, l := range qwerty {
+		i := strings.IndexRune(l.keys, r)
+		if i >= 0 {
+			return row, float64(i) + l.offset, true
+		}
+	}
+	return 0, 0, false
+}
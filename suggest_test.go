@@ -0,0 +1,34 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+var keyboardTypoTests = []struct {
+	word, suggestion string
+	want             bool
+}{
+	{word: "tge", suggestion: "the", want: true},
+	{word: "Tge", suggestion: "the", want: true},
+	{word: "rhe", suggestion: "the", want: true},
+	{word: "cst", suggestion: "cat", want: true},
+	{word: "tpe", suggestion: "the", want: false},
+	{word: "the", suggestion: "the", want: false},
+	{word: "teh", suggestion: "the", want: false},
+	{word: "th", suggestion: "the", want: false},
+	{word: "qe", suggestion: "ae", want: true},
+	{word: "qe", suggestion: "ze", want: false},
+	{word: "t1e", suggestion: "the", want: false},
+}
+
+func TestIsKeyboardTypo(t *testing.T) {
+	for _, test := range keyboardTypoTests {
+		got := isKeyboardTypo(test.word, test.suggestion)
+		if got != test.want {
+			t.Errorf("unexpected result for %q -> %q: got:%t want:%t",
+				test.word, test.suggestion, got, test.want)
+		}
+	}
+}
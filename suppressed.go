@@ -0,0 +1,70 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// suppressions records text that was not reported because of heuristics
+// or line patterns.
+type suppressions struct {
+	list []suppression
+}
+
+// suppression is a suppressed word or line and the reason it was
+// suppressed.
+type suppression struct {
+	pos    token.Position
+	text   string
+	reason string
+}
+
+// note records the suppression of word at offset off from the start of
+// the text at pos for the provided reason.
+func (s *suppressions) note(pos token.Position, off int, word, reason string) {
+	pos.Offset += off
+	pos.Column += off
+	s.list = append(s.list, suppression{pos: pos, text: word, reason: reason})
+}
+
+// noteLines records the suppression of lines in text at pos. The match
+// function returns the line pattern matching a line, or the empty string
+// if the line is not suppressed.
+func (s *suppressions) noteLines(pos token.Position, text string, match func(line string) string) {
+	var off int
+	for _, l := range strings.SplitAfter(text, "\n") {
+		line := strings.TrimSuffix(l, "\n")
+		if re := match(line); re != "" {
+			p := pos
+			p.Offset += off
+			p.Column += off
+			s.list = append(s.list, suppression{pos: p, text: strings.TrimSpace(line), reason: fmt.Sprintf("line pattern %q", re)})
+		}
+		off += len(l)
+	}
+}
+
+// report writes a report of suppressed words and lines to stdout.
+func (s *suppressions) report() {
+	sort.SliceStable(s.list, func(i, j int) bool {
+		pi, pj := s.list[i].pos, s.list[j].pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+	for _, sup := range s.list {
+		p := sup.pos
+		if p.IsValid() {
+			fmt.Printf("%v:%d:%d: suppressed %q by %s\n", rel(p.Filename), p.Line, p.Column, sup.text, sup.reason)
+		} else {
+			fmt.Printf("%v@%d: suppressed %q by %s\n", rel(p.Filename), p.Offset, sup.text, sup.reason)
+		}
+	}
+}
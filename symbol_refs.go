@@ -0,0 +1,100 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/types"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// symbolRef matches dotted references to symbols in comment text, for
+// example "pkg.Func", "Type.Method" and "recv.field".
+var symbolRef = regexp.MustCompile(`\b[\pL_][\pL\pN_]*(?:\.[\pL_][\pL\pN_]*)+\b`)
+
+// symbolRefs returns the spans of dotted symbol references in the comments
+// of f that resolve to known objects. The first element of a reference
+// must be an imported package name, a name in the package or universe
+// scope, or the receiver name of the method whose doc comment or body
+// holds the comment. Each following element must be a member of the
+// package or a field or method of the type of the previous element.
+func symbolRefs(p *packages.Package, f *ast.File) map[*ast.Comment][]span {
+	imports := make(map[string]types.Object)
+	for _, imp := range f.Imports {
+		var obj types.Object
+		if imp.Name != nil {
+			obj = p.TypesInfo.Defs[imp.Name]
+		} else {
+			obj = p.TypesInfo.Implicits[imp]
+		}
+		if obj != nil {
+			imports[obj.Name()] = obj
+		}
+	}
+	var methods []*ast.FuncDecl
+	for _, d := range f.Decls {
+		fn, ok := d.(*ast.FuncDecl)
+		if ok && fn.Recv != nil && len(fn.Recv.List) != 0 && len(fn.Recv.List[0].Names) != 0 {
+			methods = append(methods, fn)
+		}
+	}
+
+	refs := make(map[*ast.Comment][]span)
+	for _, g := range f.Comments {
+		// Find the method receiver in scope for the comment.
+		var recv types.Object
+		for _, fn := range methods {
+			start := fn.Pos()
+			if fn.Doc != nil {
+				start = fn.Doc.Pos()
+			}
+			if start <= g.Pos() && g.End() <= fn.End() {
+				recv = p.TypesInfo.Defs[fn.Recv.List[0].Names[0]]
+				break
+			}
+		}
+		for _, cm := range g.List {
+			for _, idx := range symbolRef.FindAllStringIndex(cm.Text, -1) {
+				if resolvesSymbol(p, imports, recv, strings.Split(cm.Text[idx[0]:idx[1]], ".")) {
+					refs[cm] = append(refs[cm], span{pos: idx[0], end: idx[1]})
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// resolvesSymbol returns whether the elements of a dotted symbol reference
+// resolve to a chain of known objects in the package p.
+func resolvesSymbol(p *packages.Package, imports map[string]types.Object, recv types.Object, elems []string) bool {
+	var obj types.Object
+	switch {
+	case recv != nil && recv.Name() == elems[0]:
+		obj = recv
+	case imports[elems[0]] != nil:
+		obj = imports[elems[0]]
+	case p.Types.Scope().Lookup(elems[0]) != nil:
+		obj = p.Types.Scope().Lookup(elems[0])
+	default:
+		obj = types.Universe.Lookup(elems[0])
+	}
+	if obj == nil {
+		return false
+	}
+	for _, e := range elems[1:] {
+		if pkg, ok := obj.(*types.PkgName); ok {
+			obj = pkg.Imported().Scope().Lookup(e)
+		} else {
+			obj, _, _ = types.LookupFieldOrMethod(obj.Type(), true, obj.Pkg(), e)
+		}
+		if obj == nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,217 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template/parse"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// templateExts is the set of file extensions of Go template files and
+// whether the extension indicates an HTML template.
+var templateExts = map[string]bool{
+	".tmpl":   false,
+	".gotmpl": false,
+	".tpl":    false,
+	".gohtml": true,
+}
+
+// templateFiles returns the paths of Go template files used by the provided
+// packages and whether each is an HTML template. Embedded files with a
+// template file extension are included, as are files named by constant
+// arguments to the ParseFiles, ParseGlob and ParseFS functions and methods
+// of the text/template and html/template packages. ParseFiles and ParseGlob
+// paths are resolved relative to the directory of the calling source file
+// and ParseFS patterns are matched against the package's embedded files.
+func templateFiles(pkgs []*packages.Package) map[string]bool {
+	templates := make(map[string]bool)
+	for _, p := range pkgs {
+		for _, e := range p.EmbedFiles {
+			html, ok := templateExts[filepath.Ext(e)]
+			if ok {
+				templates[e] = html
+			}
+		}
+		for _, f := range p.Syntax {
+			dir := filepath.Dir(p.Fset.Position(f.Pos()).Filename)
+			ast.Inspect(f, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				fn, ok := p.TypesInfo.Uses[sel.Sel].(*types.Func)
+				if !ok || fn.Pkg() == nil {
+					return true
+				}
+				var html bool
+				switch fn.Pkg().Path() {
+				case "text/template":
+				case "html/template":
+					html = true
+				default:
+					return true
+				}
+				args := call.Args
+				switch fn.Name() {
+				case "ParseFiles", "ParseGlob":
+				case "ParseFS":
+					if len(args) == 0 {
+						return true
+					}
+					args = args[1:]
+				default:
+					return true
+				}
+				for _, a := range args {
+					tv, ok := p.TypesInfo.Types[a]
+					if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+						continue
+					}
+					name := constant.StringVal(tv.Value)
+					switch fn.Name() {
+					case "ParseFiles":
+						templates[filepath.Join(dir, name)] = html
+					case "ParseGlob":
+						matches, _ := filepath.Glob(filepath.Join(dir, name))
+						for _, m := range matches {
+							templates[m] = html
+						}
+					case "ParseFS":
+						for _, e := range p.EmbedFiles {
+							rel, err := filepath.Rel(dir, e)
+							if err != nil {
+								continue
+							}
+							if ok, _ := filepath.Match(filepath.FromSlash(name), rel); ok {
+								templates[e] = html
+							}
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+	return templates
+}
+
+// addTemplateSources adds the Go template files in the directories of the
+// provided packages to templates, whether or not they are used by the
+// packages. This includes template sources of generated files, such as
+// README.tmpl.md. Files are template files if their extension, or the
+// extension preceding it, is a template file extension. A template file is
+// an HTML template if its extension is .gohtml, .html or .htm.
+func addTemplateSources(templates map[string]bool, pkgs []*packages.Package) error {
+	seen := make(map[string]bool)
+	for _, p := range pkgs {
+		if len(p.GoFiles) == 0 {
+			continue
+		}
+		dir := filepath.Dir(p.GoFiles[0])
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if !e.Type().IsRegular() {
+				continue
+			}
+			html, ok := isTemplateSource(e.Name())
+			if !ok {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if _, ok := templates[path]; !ok {
+				templates[path] = html
+			}
+		}
+	}
+	return nil
+}
+
+// isTemplateSource returns whether the file name is a Go template file name
+// and whether it is an HTML template.
+func isTemplateSource(name string) (html, ok bool) {
+	ext := filepath.Ext(name)
+	html, ok = templateExts[ext]
+	if ok {
+		return html, true
+	}
+	_, ok = templateExts[filepath.Ext(strings.TrimSuffix(name, ext))]
+	if !ok {
+		return false, false
+	}
+	return ext == ".html" || ext == ".htm", true
+}
+
+// maskTemplate returns the Go template text with all text other than the
+// literal text portions of the template replaced with spaces. If html is
+// true, HTML markup in the literal text is also replaced. If the template
+// cannot be parsed, text is returned unaltered.
+func maskTemplate(text string, html bool) string {
+	trees := make(map[string]*parse.Tree)
+	t := parse.New("template")
+	t.Mode = parse.SkipFuncCheck
+	_, err := t.Parse(text, "", "", trees)
+	if err != nil {
+		return text
+	}
+	keep := make([]bool, len(text))
+	var walk func(parse.Node)
+	walk = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, n := range n.Nodes {
+				walk(n)
+			}
+		case *parse.TextNode:
+			for i := int(n.Pos); i < int(n.Pos)+len(n.Text) && i < len(keep); i++ {
+				keep[i] = true
+			}
+		case *parse.IfNode:
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.List)
+			walk(n.ElseList)
+		}
+	}
+	walk(t.Root)
+	for _, tree := range trees {
+		walk(tree.Root)
+	}
+	b := []byte(text)
+	for i, k := range keep {
+		if !k && b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+	text = string(b)
+	if html {
+		text = markup.ReplaceAllStringFunc(text, blank)
+	}
+	return text
+}
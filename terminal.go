@@ -0,0 +1,181 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminal is a terminal in raw mode that findings are reviewed on with
+// single key presses.
+type terminal struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	fd  int
+	old unix.Termios
+}
+
+// newTerminal returns a terminal reading key presses from r and writing to
+// w. It returns an error if r and w are not both terminals or r could not
+// be put into raw mode. The terminal must be restored when it is no longer
+// needed.
+func newTerminal(r io.Reader, w io.Writer) (*terminal, error) {
+	in, ok := r.(*os.File)
+	if !ok {
+		return nil, errors.New("input is not a terminal")
+	}
+	out, ok := w.(*os.File)
+	if !ok {
+		return nil, errors.New("output is not a terminal")
+	}
+	_, err := unix.IoctlGetTermios(int(out.Fd()), ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+	fd := int(in.Fd())
+	old, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+	t := &terminal{in: bufio.NewReader(in), out: w, fd: fd, old: *old}
+	err = t.raw()
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// raw puts the terminal into raw mode so that each key press is read
+// as it is made without being echoed. Interrupt keys are read as keys
+// so that the terminal is always restored.
+func (t *terminal) raw() error {
+	raw := t.old
+	raw.Lflag &^= unix.ICANON | unix.ECHO | unix.ISIG
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	return unix.IoctlSetTermios(t.fd, ioctlSetTermios, &raw)
+}
+
+// restore returns the terminal to the mode it was in when it was opened.
+func (t *terminal) restore() error {
+	return unix.IoctlSetTermios(t.fd, ioctlSetTermios, &t.old)
+}
+
+// readLine reads a line from the terminal with echo, returning to raw
+// mode once it has been read.
+func (t *terminal) readLine() (string, error) {
+	err := t.restore()
+	if err != nil {
+		return "", err
+	}
+	line, err := t.in.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), t.raw()
+}
+
+// Keys used to review findings on a terminal.
+const (
+	keyReplace   = 'r'
+	keyAdd       = 'a'
+	keySkip      = 's'
+	keyQuit      = 'q'
+	keyInterrupt = 3 // ^C
+	keyEOF       = 4 // ^D
+)
+
+// promptReplace is the key action for reading a typed replacement.
+const promptReplace = "replace"
+
+// maxKeySuggestions is the number of suggestions that can be selected
+// with a single key.
+const maxKeySuggestions = 9
+
+// promptFinding shows the finding f, the n'th of total, and its
+// suggestions on a cleared screen and reads the action to take as a key
+// press. The returned action is decideFix with the replacement, decideAdd,
+// decideIgnore or, if the review is quit, promptEnd.
+func (t *terminal) promptFinding(c *checker, f finding, n, total int) (action, replacement string) {
+	c.useDictionary(f.pos.Filename)
+	suggestions := c.suggestionsFor(f.word)
+	if len(suggestions) > maxKeySuggestions {
+		suggestions = suggestions[:maxKeySuggestions]
+	}
+	for {
+		// Clear the screen and move to the top left.
+		fmt.Fprint(t.out, "\x1b[H\x1b[2J")
+		fmt.Fprintf(t.out, "finding %d of %d\n\n", n, total)
+		fmt.Fprintf(t.out, "%s: %q is %s in %s\n\n", f.position(), f.word, f.note, f.where)
+		fmt.Fprintf(t.out, "\t%s\n\n", c.highlightedLine(f))
+		for i, s := range suggestions {
+			fmt.Fprintf(t.out, "  %d  %s\n", i+1, s)
+		}
+		if len(suggestions) != 0 {
+			fmt.Fprintln(t.out)
+		}
+		fmt.Fprintf(t.out, "[%c] replace  [%c] add to .words  [%c] skip  [%c] quit", keyReplace, keyAdd, keySkip, keyQuit)
+		switch len(suggestions) {
+		case 0:
+		case 1:
+			fmt.Fprint(t.out, "  [1] accept suggestion")
+		default:
+			fmt.Fprintf(t.out, "  [1-%d] accept suggestion", len(suggestions))
+		}
+		fmt.Fprint(t.out, "\n")
+
+		key, err := t.in.ReadByte()
+		if err != nil {
+			return promptEnd, ""
+		}
+		action, replacement, ok := keyAction(key, suggestions)
+		if !ok {
+			continue
+		}
+		if action != promptReplace {
+			return action, replacement
+		}
+		fmt.Fprintf(t.out, "replace %q with: ", f.word)
+		replacement, err = t.readLine()
+		if err != nil {
+			return promptEnd, ""
+		}
+		if replacement != "" {
+			return decideFix, replacement
+		}
+	}
+}
+
+// keyAction returns the action for the key press key when reviewing a
+// finding with the given suggestions. If key is the replace key, action
+// is promptReplace and the replacement must be read. If ok is false, the
+// key has no action.
+func keyAction(key byte, suggestions []string) (action, replacement string, ok bool) {
+	switch key {
+	case keyReplace:
+		return promptReplace, "", true
+	case keyAdd:
+		return decideAdd, "", true
+	case keySkip, ' ', '\r', '\n':
+		return decideIgnore, "", true
+	case keyQuit, keyInterrupt, keyEOF:
+		return promptEnd, "", true
+	}
+	if '1' <= key && key <= '9' {
+		i := int(key - '1')
+		if i < len(suggestions) {
+			return decideFix, suggestions[i], true
+		}
+	}
+	return "", "", false
+}
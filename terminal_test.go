@@ -0,0 +1,38 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+var keyActionTests = []struct {
+	key             byte
+	wantAction      string
+	wantReplacement string
+	wantOK          bool
+}{
+	{key: '1', wantAction: decideFix, wantReplacement: "colored", wantOK: true},
+	{key: '2', wantAction: decideFix, wantReplacement: "couriered", wantOK: true},
+	{key: '3', wantOK: false},
+	{key: '0', wantOK: false},
+	{key: 'r', wantAction: promptReplace, wantOK: true},
+	{key: 'a', wantAction: decideAdd, wantOK: true},
+	{key: 's', wantAction: decideIgnore, wantOK: true},
+	{key: ' ', wantAction: decideIgnore, wantOK: true},
+	{key: '\r', wantAction: decideIgnore, wantOK: true},
+	{key: 'q', wantAction: promptEnd, wantOK: true},
+	{key: keyInterrupt, wantAction: promptEnd, wantOK: true},
+	{key: 'x', wantOK: false},
+}
+
+func TestKeyAction(t *testing.T) {
+	suggestions := []string{"colored", "couriered"}
+	for _, test := range keyActionTests {
+		action, replacement, ok := keyAction(test.key, suggestions)
+		if action != test.wantAction || replacement != test.wantReplacement || ok != test.wantOK {
+			t.Errorf("unexpected result for key %q: got:%q %q %t want:%q %q %t",
+				test.key, action, replacement, ok, test.wantAction, test.wantReplacement, test.wantOK)
+		}
+	}
+}
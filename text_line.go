@@ -0,0 +1,101 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"strings"
+)
+
+// textLine is a line of text from a source other than Go code, such as
+// a commit message, a changelog, a message catalog, a template or a
+// go.mod file.
+type textLine struct {
+	name string // name is the name of the source of the text.
+	kind string // kind is the kind of text, used for reporting.
+	line int    // line is the line number in the text.
+	off  int    // off is the offset of the line in the text.
+	text string
+}
+
+// Kinds of text lines.
+const (
+	commitMessage  = "commit message"
+	changelog      = "changelog"
+	messageCatalog = "message catalog"
+	goTemplate     = "template"
+	modFile        = "module file"
+)
+
+// textLines returns the non-blank lines of text as textLines with the
+// provided name and kind.
+func textLines(name, kind, text string) []*textLine {
+	var (
+		lines []*textLine
+		off   int
+	)
+	for n, l := range strings.SplitAfter(text, "\n") {
+		line := &textLine{name: name, kind: kind, line: n + 1, off: off, text: strings.TrimSuffix(l, "\n")}
+		off += len(l)
+		if strings.TrimSpace(line.text) == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Pos and End implement ast.Node.
+func (l *textLine) Pos() token.Pos { return 1 }
+func (l *textLine) End() token.Pos { return l.Pos() + token.Pos(len(l.text)) }
+
+// Position implements positioner.
+func (l *textLine) Position(pos token.Pos) token.Position {
+	return token.Position{
+		Filename: l.name,
+		Offset:   l.off + int(pos) - 1,
+		Line:     l.line,
+		Column:   int(pos),
+	}
+}
+
+// checkTextFiles checks the lines of the files at paths as text of the
+// given kind, skipping ignored files. If prepare is not nil, it is called
+// with the path and contents of each file and returns the text to check,
+// with any content that should not be checked masked, and style findings
+// for the file keyed by line number.
+func (c *checker) checkTextFiles(paths []string, kind string, prepare func(path string, b []byte) (string, map[int][]finding, error)) error {
+	for _, path := range paths {
+		ignored, err := c.ignorer.isIgnored(path)
+		if err != nil {
+			return err
+		}
+		if ignored {
+			continue
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read %s: %v", kind, err)
+		}
+		text := string(b)
+		var notices map[int][]finding
+		if prepare != nil {
+			text, notices, err = prepare(path, b)
+			if err != nil {
+				return err
+			}
+		}
+		for _, l := range textLines(path, kind, text) {
+			if found, ok := notices[l.line]; ok {
+				c.style[l] = found
+			}
+			c.fileset = l
+			c.check(l.text, l)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// urlSet is a read-only set of URLs.
+type urlSet interface {
+	// has returns whether the URL u is in the set.
+	has(u string) bool
+}
+
+// urlMap is a urlSet backed by a map. A nil urlMap is empty.
+type urlMap map[string]bool
+
+// has implements urlSet.
+func (m urlMap) has(u string) bool { return m[u] }
+
+// urlChecker confirms that URLs in text point to reachable targets. It
+// holds no references to checker or dictionary state other than the
+// read-only set of ignored URLs, and returns its results rather than
+// recording them, so it may be used independently of the checker.
+type urlChecker struct {
+	// ignored is the set of URLs
+	// that are not confirmed.
+	ignored urlSet
+
+	// client is the HTTP client used to
+	// confirm URLs. If it is nil the
+	// default client is used.
+	client *http.Client
+}
+
+// unreachable returns a list of unreachable URL targets in text with the
+// HTTP status or error reasons included. URLs starting at offsets in text
+// for which include returns false are not confirmed.
+func (c urlChecker) unreachable(text string, include func(off int) bool) []finding {
+	client := c.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var dst []finding
+	for _, idx := range urls.FindAllStringIndex(text, -1) {
+		if !include(idx[0]) {
+			continue
+		}
+		u := text[idx[0]:idx[1]]
+		if c.ignored != nil && c.ignored.has(u) {
+			continue
+		}
+		parsed, err := url.Parse(u)
+		if err != nil {
+			continue
+		}
+		switch parsed.Scheme {
+		case "http", "https":
+		default:
+			continue
+		}
+		// While servers may treat GET and HEAD differently, resulting
+		// in false positives and negatives, use of HEAD is justified by
+		// https://datatracker.ietf.org/doc/html/rfc2616/#section-9.4.
+		//
+		//  This method is often used for testing hypertext links for
+		//  validity, accessibility, and recent modification.
+		//
+		resp, err := client.Head(u)
+		if err != nil {
+			dst = append(dst, finding{
+				category: referenceCategory,
+				word:     u,
+				span:     span{pos: idx[0], end: idx[1]},
+				note:     fmt.Sprintf("unreachable (%v)", err),
+			})
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		switch statusClass := resp.StatusCode / 100; statusClass {
+		case 4, 5:
+			dst = append(dst, finding{
+				category: referenceCategory,
+				word:     u,
+				span:     span{pos: idx[0], end: idx[1]},
+				note:     fmt.Sprintf("unreachable (%v)", resp.Status),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,45 @@
+// Copyright ©2022 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestURLCheckerUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	text := "// See " + srv.URL + "/ok, " + srv.URL + "/missing and " + srv.URL + "/missing/ignored."
+	ignored := srv.URL + "/missing/ignored"
+	c := urlChecker{ignored: urlMap{ignored: true}, client: srv.Client()}
+
+	got := c.unreachable(text, func(int) bool { return true })
+	pos := strings.Index(text, srv.URL+"/missing ")
+	want := []finding{{
+		category: referenceCategory,
+		word:     srv.URL + "/missing",
+		span:     span{pos: pos, end: pos + len(srv.URL+"/missing")},
+		note:     "unreachable (404 Not Found)",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected result:\n%s", cmp.Diff(got, want, cmp.AllowUnexported(finding{}, span{})))
+	}
+
+	got = c.unreachable(text, func(off int) bool { return off != pos })
+	if len(got) != 0 {
+		t.Errorf("unexpected result for excluded URL: %v", got)
+	}
+}